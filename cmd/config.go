@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bednarradek/php-deployer/internal"
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/spf13/cobra"
+)
+
+// addConfigFlags add flags used for loading of config
+func addConfigFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("config", "c", "", "Path to config file")
+	cmd.Flags().StringP("env", "e", "", "Name of config profile")
+	cmd.Flags().StringP("format", "f", "", "Format of config file (json, yaml, toml), detected by extension when empty")
+}
+
+// loadConfig load config by config flags, returns config and its path
+func loadConfig(cmd *cobra.Command) (*internal.FtpConfig, string) {
+	config, configPath, err := readConfig(cmd)
+	if err != nil {
+		log.Fatalf("Error while loading config: %s", err)
+	}
+	return config, configPath
+}
+
+// readConfig load config by config flags, used directly when config is loaded again during deploy
+func readConfig(cmd *cobra.Command) (*internal.FtpConfig, string, error) {
+	configPath, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, "", fmt.Errorf("error while getting config flag: %w", err)
+	}
+	env, err := cmd.Flags().GetString("env")
+	if err != nil {
+		return nil, "", fmt.Errorf("error while getting env flag: %w", err)
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return nil, "", fmt.Errorf("error while getting format flag: %w", err)
+	}
+	config, err := internal.NewConfigLoader(file_system.NewSystemReader(), format).Load(cmd.Context(), configPath, env)
+	if err != nil {
+		return nil, "", fmt.Errorf("error while loading config file: %w", err)
+	}
+	return config, configPath, nil
+}
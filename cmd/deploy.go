@@ -1,12 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/bednarradek/php-deployer/internal"
 	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/secret"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -20,33 +25,42 @@ var deployCmd = &cobra.Command{
 
 		ctx := cmd.Context()
 
+		// mask resolved secrets in all logs
+		masker := secret.NewMasker()
+		log.SetOutput(secret.NewMaskingWriter(os.Stderr, masker))
+		logrus.SetOutput(secret.NewMaskingWriter(os.Stderr, masker))
+
 		t, err := cmd.Flags().GetString("type")
 		if err != nil {
 			log.Fatalf("Error while getting type flag: %s", err)
 		}
-		configPath, err := cmd.Flags().GetString("config")
+		allowMassDelete, err := cmd.Flags().GetBool("allow-mass-delete")
 		if err != nil {
-			log.Fatalf("Error while getting config flag: %s", err)
+			log.Fatalf("Error while getting allow-mass-delete flag: %s", err)
 		}
-
-		configContent, err := file_system.NewSystemReader().Read(ctx, configPath)
+		rehash, err := cmd.Flags().GetBool("rehash")
 		if err != nil {
-			log.Fatalf("Error while reading config file: %s", err)
+			log.Fatalf("Error while getting rehash flag: %s", err)
 		}
 
 		switch t {
 		case "ftp":
-			config := new(internal.FtpConfig)
-			if err := json.Unmarshal(configContent, config); err != nil {
-				log.Fatalf("Error while unmarshalling config file: %s", err)
+			config, configPath := loadConfig(cmd)
+			if errs := internal.NewConfigValidator(file_system.NewSystemReader()).Validate(ctx, config); len(errs) > 0 {
+				log.Fatalf("Config is invalid: %s", errors.Join(errs...))
 			}
-			deployer, err := internal.NewFtpDeployer(config)
+			deployer, err := internal.NewFtpDeployer(config, configPath, masker, internal.DeployOptions{
+				AllowMassDelete: allowMassDelete,
+				Rehash:          rehash,
+			})
 			if err != nil {
 				log.Fatalf("Error while creating deployer: %s", err)
 			}
 			defer func() {
 				deployer.Close()
 			}()
+			stop := watchUploadRates(cmd, deployer)
+			defer stop()
 			if err := deployer.Deploy(ctx); err != nil {
 				log.Fatalf("Error while deploying: %s", err)
 			}
@@ -56,9 +70,39 @@ var deployCmd = &cobra.Command{
 	},
 }
 
+// watchUploadRates load config again on SIGHUP and apply its upload rates to running deploy, returns function stopping the watch
+func watchUploadRates(cmd *cobra.Command, deployer *internal.FtpDeployer) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-signals:
+				config, _, err := readConfig(cmd)
+				if err != nil {
+					logrus.Errorf("Upload rate was not changed: %s", err)
+					continue
+				}
+				if err := deployer.SetUploadRates(config.Sync); err != nil {
+					logrus.Errorf("Upload rate was not changed: %s", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(deployCmd)
 
 	deployCmd.Flags().StringP("type", "t", "ftp", "Type of deployer")
-	deployCmd.Flags().StringP("config", "c", "", "Path to config file")
+	addConfigFlags(deployCmd)
+	deployCmd.Flags().Bool("allow-mass-delete", false, "Allow sync to delete more remote objects than max_deletions")
+	deployCmd.Flags().Bool("rehash", false, "Ignore local hash cache and hash all files again")
 }
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/internal"
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/spf13/cobra"
+)
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Inspect variables used by config templates",
+	Long:  ``,
+}
+
+// envCheckCmd list variables referenced by templates and fail when some of them are not defined
+var envCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "List variables referenced by templates and report undefined ones",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		config, configPath := loadConfig(cmd)
+
+		checker, err := internal.NewConfigEnvChecker(ctx, file_system.NewSystemReader(), config, configPath)
+		if err != nil {
+			log.Fatalf("Error while creating env checker: %s", err)
+		}
+		references, err := checker.Check(ctx, config)
+		if err != nil {
+			log.Fatalf("Error while checking templates: %s", err)
+		}
+
+		missing := 0
+		for _, r := range references {
+			if len(r.Variables) == 0 {
+				continue
+			}
+			fmt.Printf("%s: %s\n", r.Location, strings.Join(r.Variables, ", "))
+			if len(r.Missing) > 0 {
+				fmt.Printf("  missing: %s\n", strings.Join(r.Missing, ", "))
+				missing += len(r.Missing)
+			}
+		}
+		if missing > 0 {
+			log.Fatalf("%d undefined variables found", missing)
+		}
+		fmt.Println("All variables are defined")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envCheckCmd)
+
+	addConfigFlags(envCheckCmd)
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/generator"
+	"github.com/bednarradek/php-deployer/pkg/secret"
+	"github.com/spf13/cobra"
+)
+
+// secretsCmd represents the secrets command
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage encrypted secrets file",
+	Long: fmt.Sprintf(`Manage file of AES-GCM encrypted secrets.
+Key is read from %s or from file in %s.`, secret.KeyEnv, secret.KeyFileEnv),
+}
+
+var secretsEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt plain NAME=VALUE pairs (from input file or stdin) into secrets file",
+	Run: func(cmd *cobra.Command, args []string) {
+		path, key, store := openStore(cmd, true)
+		input, err := cmd.Flags().GetString("input")
+		if err != nil {
+			log.Fatalf("Error while getting input flag: %s", err)
+		}
+		var content []byte
+		if input == "" {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			content, err = os.ReadFile(input)
+		}
+		if err != nil {
+			log.Fatalf("Error while reading input: %s", err)
+		}
+		values, err := generator.ParseDotEnv(content)
+		if err != nil {
+			log.Fatalf("Error while parsing input: %s", err)
+		}
+		for name, value := range values {
+			if err := store.Set(key, name, value); err != nil {
+				log.Fatalf("Error while encrypting secret %s: %s", name, err)
+			}
+		}
+		if err := store.Write(path); err != nil {
+			log.Fatalf("Error while writing secrets file: %s", err)
+		}
+	},
+}
+
+var secretsDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Print decrypted secrets as NAME=VALUE pairs",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, key, store := openStore(cmd, false)
+		content, err := decryptStore(store, key)
+		if err != nil {
+			log.Fatalf("Error while decrypting secrets file: %s", err)
+		}
+		fmt.Print(string(content))
+	},
+}
+
+var secretsEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit decrypted secrets in $EDITOR and encrypt them back",
+	Run: func(cmd *cobra.Command, args []string) {
+		path, key, store := openStore(cmd, true)
+		if err := editStore(path, key, store); err != nil {
+			log.Fatalf("Error while editing secrets file: %s", err)
+		}
+	},
+}
+
+// editStore edit decrypted secrets in temporary file, the file with plain values is removed on every exit path
+func editStore(path string, key []byte, store *secret.Store) error {
+	content, err := decryptStore(store, key)
+	if err != nil {
+		return fmt.Errorf("error while decrypting secrets file: %w", err)
+	}
+	tmp, err := os.CreateTemp("", "deployer-secrets-*.env")
+	if err != nil {
+		return fmt.Errorf("error while creating temporary file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(content); err != nil {
+		return fmt.Errorf("error while writing temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error while closing temporary file: %w", err)
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	editorCmd := exec.Command("sh", "-c", fmt.Sprintf("%s %s", editor, strconv.Quote(tmp.Name())))
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("error while running editor: %w", err)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("error while reading temporary file: %w", err)
+	}
+	values, err := generator.ParseDotEnv(edited)
+	if err != nil {
+		return fmt.Errorf("error while parsing edited secrets: %w", err)
+	}
+	result, err := secret.NewStore()
+	if err != nil {
+		return fmt.Errorf("error while creating secrets store: %w", err)
+	}
+	for name, value := range values {
+		if err := result.Set(key, name, value); err != nil {
+			return fmt.Errorf("error while encrypting secret %s: %w", name, err)
+		}
+	}
+	if err := result.Write(path); err != nil {
+		return fmt.Errorf("error while writing secrets file: %w", err)
+	}
+	return nil
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set NAME [VALUE]",
+	Short: "Set one secret, value is read from stdin when omitted",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, key, store := openStore(cmd, true)
+		var value string
+		if len(args) == 2 {
+			value = args[1]
+		} else {
+			content, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				log.Fatalf("Error while reading value from stdin: %s", err)
+			}
+			value = strings.TrimRight(string(content), "\r\n")
+		}
+		if err := store.Set(key, args[0], value); err != nil {
+			log.Fatalf("Error while encrypting secret %s: %s", args[0], err)
+		}
+		if err := store.Write(path); err != nil {
+			log.Fatalf("Error while writing secrets file: %s", err)
+		}
+	},
+}
+
+// openStore load key and secrets file from file flag, missing file is created when allowed (store will be written)
+func openStore(cmd *cobra.Command, create bool) (string, []byte, *secret.Store) {
+	path, err := cmd.Flags().GetString("file")
+	if err != nil {
+		log.Fatalf("Error while getting file flag: %s", err)
+	}
+	key, err := secret.LoadKey()
+	if err != nil {
+		log.Fatalf("Error while loading key: %s", err)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) && create {
+		store, err := secret.NewStore()
+		if err != nil {
+			log.Fatalf("Error while creating secrets file: %s", err)
+		}
+		return path, key, store
+	}
+	store, err := secret.ReadStore(path)
+	if err != nil {
+		log.Fatalf("Error while reading secrets file: %s", err)
+	}
+	return path, key, store
+}
+
+func decryptStore(store *secret.Store, key []byte) ([]byte, error) {
+	values, err := store.Decrypt(key)
+	if err != nil {
+		return nil, err
+	}
+	content := new(bytes.Buffer)
+	for _, name := range store.Names() {
+		content.WriteString(fmt.Sprintf("%s=%s\n", name, strconv.Quote(values[name])))
+	}
+	return content.Bytes(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsEncryptCmd, secretsDecryptCmd, secretsEditCmd, secretsSetCmd)
+
+	secretsCmd.PersistentFlags().StringP("file", "f", "secrets.json", "Path to secrets file")
+	secretsEncryptCmd.Flags().StringP("input", "i", "", "Path to plain NAME=VALUE file, stdin is used when empty")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/bednarradek/php-deployer/internal"
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate config file or print its JSON Schema",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		schema, err := cmd.Flags().GetBool("schema")
+		if err != nil {
+			log.Fatalf("Error while getting schema flag: %s", err)
+		}
+		if schema {
+			b, err := json.MarshalIndent(internal.ConfigSchema(), "", "  ")
+			if err != nil {
+				log.Fatalf("Error while marshalling schema: %s", err)
+			}
+			fmt.Println(string(b))
+			return
+		}
+
+		config, configPath := loadConfig(cmd)
+		errs := internal.NewConfigValidator(file_system.NewSystemReader()).Validate(ctx, config)
+		for _, e := range errs {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		if len(errs) > 0 {
+			log.Fatalf("Config %s is invalid: %d errors found", configPath, len(errs))
+		}
+		fmt.Printf("Config %s is valid\n", configPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	addConfigFlags(validateCmd)
+	validateCmd.Flags().Bool("schema", false, "Print JSON Schema of config")
+}
@@ -3,10 +3,14 @@ module github.com/bednarradek/php-deployer
 go 1.21.0
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/bednarradek/ftp v0.0.2
+	github.com/cespare/xxhash/v2 v2.3.0
 	github.com/silenceper/pool v1.0.0
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/crypto v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bednarradek/php-deployer/pkg/action"
+	"github.com/bednarradek/php-deployer/pkg/generator"
+)
+
+// newCommandAction create command action from config, command, arguments and directory are generated from templates
+func newCommandAction(ctx context.Context, g generator.Generator, actionConfig ActionConfig, source string) (*action.CommandAction, error) {
+	args := new(CommandActionArguments)
+	if err := decodeArguments(actionConfig.Arguments, args); err != nil {
+		return nil, fmt.Errorf("newCommandAction error while decoding arguments: %w", err)
+	}
+	if args.Command == "" {
+		return nil, fmt.Errorf("newCommandAction missing command argument for action: %s", actionConfig.Type)
+	}
+	command, err := generateString(ctx, g, args.Command)
+	if err != nil {
+		return nil, fmt.Errorf("newCommandAction error while generating command: %w", err)
+	}
+	commandArgs := make([]string, len(args.Args))
+	for i, a := range args.Args {
+		if commandArgs[i], err = generateString(ctx, g, a); err != nil {
+			return nil, fmt.Errorf("newCommandAction error while generating argument %d: %w", i, err)
+		}
+	}
+	dir, err := generateString(ctx, g, args.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("newCommandAction error while generating dir: %w", err)
+	}
+	return action.NewCommandAction(command, commandArgs, fmt.Sprintf("%s%s", source, dir)), nil
+}
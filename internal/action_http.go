@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bednarradek/php-deployer/pkg/action"
+	"github.com/bednarradek/php-deployer/pkg/generator"
+	"github.com/bednarradek/php-deployer/pkg/secret"
+)
+
+// decodeArguments convert generic arguments from config to typed structure
+func decodeArguments(arguments map[string]interface{}, out any) error {
+	b, err := json.Marshal(arguments)
+	if err != nil {
+		return fmt.Errorf("decodeArguments error while marshalling arguments: %w", err)
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("decodeArguments error while unmarshalling arguments: %w", err)
+	}
+	return nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("parseDuration error while parsing duration %s: %w", value, err)
+	}
+	return d, nil
+}
+
+func generateString(ctx context.Context, g generator.Generator, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	res, err := g.Generate(ctx, []byte(value))
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
+// newHttpAction create http action from config, all string arguments are generated from templates
+func newHttpAction(ctx context.Context, g generator.Generator, masker *secret.Masker, actionConfig ActionConfig) (*action.HttpAction, error) {
+	args := new(HttpActionArguments)
+	if err := decodeArguments(actionConfig.Arguments, args); err != nil {
+		return nil, fmt.Errorf("newHttpAction error while decoding arguments: %w", err)
+	}
+	if args.Url == "" {
+		return nil, fmt.Errorf("newHttpAction missing url argument for action: %s", actionConfig.Type)
+	}
+	if args.Method == "" {
+		return nil, fmt.Errorf("newHttpAction missing method argument for action: %s", actionConfig.Type)
+	}
+
+	url, err := generateString(ctx, g, args.Url)
+	if err != nil {
+		return nil, fmt.Errorf("newHttpAction error while generating url: %w", err)
+	}
+	method, err := generateString(ctx, g, args.Method)
+	if err != nil {
+		return nil, fmt.Errorf("newHttpAction error while generating method: %w", err)
+	}
+	headers := make(map[string]string, len(args.Headers))
+	for k, v := range args.Headers {
+		res, err := generateString(ctx, g, v)
+		if err != nil {
+			return nil, fmt.Errorf("newHttpAction error while generating header %s: %w", k, err)
+		}
+		headers[k] = res
+	}
+	body, err := generateString(ctx, g, args.Body)
+	if err != nil {
+		return nil, fmt.Errorf("newHttpAction error while generating body: %w", err)
+	}
+
+	options, err := newHttpOptions(ctx, g, masker, args)
+	if err != nil {
+		return nil, fmt.Errorf("newHttpAction error while creating options: %w", err)
+	}
+
+	return action.NewHttpAction(url, method, headers, []byte(body), options), nil
+}
+
+// credentials used for auth are masked in logs
+func newHttpOptions(ctx context.Context, g generator.Generator, masker *secret.Masker, args *HttpActionArguments) (action.HttpOptions, error) {
+	options := action.HttpOptions{
+		Retry:          action.RetryPolicy{Attempts: args.Retry.Attempts},
+		ExpectedStatus: args.ExpectedStatus,
+		Tls: action.TlsOptions{
+			CaFile:   args.Tls.CaFile,
+			CertFile: args.Tls.CertFile,
+			KeyFile:  args.Tls.KeyFile,
+			Insecure: args.Tls.Insecure,
+		},
+	}
+	var err error
+	if options.Timeout, err = parseDuration(args.Timeout); err != nil {
+		return options, fmt.Errorf("newHttpOptions invalid timeout: %w", err)
+	}
+	if options.Retry.Delay, err = parseDuration(args.Retry.Delay); err != nil {
+		return options, fmt.Errorf("newHttpOptions invalid retry delay: %w", err)
+	}
+	if options.Poll.Timeout, err = parseDuration(args.Poll.Timeout); err != nil {
+		return options, fmt.Errorf("newHttpOptions invalid poll timeout: %w", err)
+	}
+	if options.Poll.Interval, err = parseDuration(args.Poll.Interval); err != nil {
+		return options, fmt.Errorf("newHttpOptions invalid poll interval: %w", err)
+	}
+
+	for _, a := range args.Assert {
+		value, err := generateString(ctx, g, a.Value)
+		if err != nil {
+			return options, fmt.Errorf("newHttpOptions error while generating assertion value: %w", err)
+		}
+		switch a.Type {
+		case HttpAssertionContains:
+			options.Assertions = append(options.Assertions, action.NewContainsAssertion(value))
+		case HttpAssertionRegex:
+			assertion, err := action.NewRegexAssertion(value)
+			if err != nil {
+				return options, fmt.Errorf("newHttpOptions invalid regex assertion: %w", err)
+			}
+			options.Assertions = append(options.Assertions, assertion)
+		case HttpAssertionJsonPath:
+			if a.Path == "" {
+				return options, fmt.Errorf("newHttpOptions missing path for json_path assertion")
+			}
+			options.Assertions = append(options.Assertions, action.NewJsonPathAssertion(a.Path, value))
+		default:
+			return options, fmt.Errorf("newHttpOptions unknown assertion type: %s", a.Type)
+		}
+	}
+
+	switch args.Auth.Type {
+	case "":
+	case HttpAuthBasic:
+		user, err := generateString(ctx, g, args.Auth.User)
+		if err != nil {
+			return options, fmt.Errorf("newHttpOptions error while generating auth user: %w", err)
+		}
+		password, err := generateString(ctx, g, args.Auth.Password)
+		if err != nil {
+			return options, fmt.Errorf("newHttpOptions error while generating auth password: %w", err)
+		}
+		masker.Add(password)
+		options.Auth = action.NewBasicAuth(user, password)
+	case HttpAuthBearer:
+		token, err := generateString(ctx, g, args.Auth.Token)
+		if err != nil {
+			return options, fmt.Errorf("newHttpOptions error while generating auth token: %w", err)
+		}
+		masker.Add(token)
+		options.Auth = action.NewBearerAuth(token)
+	default:
+		return options, fmt.Errorf("newHttpOptions unknown auth type: %s", args.Auth.Type)
+	}
+
+	return options, nil
+}
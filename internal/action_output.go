@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bednarradek/php-deployer/pkg/action"
+)
+
+// StepOutputs outputs of actions indexed by action id and output name, available in templates as {{.steps.<id>.<name>}}
+type StepOutputs map[string]map[string]string
+
+func newExtractor(outputConfig OutputConfig) (action.Extractor, error) {
+	switch outputConfig.Type {
+	case OutputJsonPath:
+		if outputConfig.Path == "" {
+			return nil, fmt.Errorf("newExtractor missing path for json_path output")
+		}
+		return action.NewJsonPathExtractor(outputConfig.Path), nil
+	case OutputRegex:
+		return action.NewRegexExtractor(outputConfig.Regex)
+	case OutputHeader:
+		if outputConfig.Name == "" {
+			return nil, fmt.Errorf("newExtractor missing name for header output")
+		}
+		return action.NewHeaderExtractor(outputConfig.Name), nil
+	case OutputStdout:
+		return action.NewStdoutExtractor(), nil
+	default:
+		return nil, fmt.Errorf("newExtractor unknown output type: %s", outputConfig.Type)
+	}
+}
+
+// newOutputExtractors compile extractors of all outputs declared by action, so invalid output is reported
+// before action is run
+func newOutputExtractors(actionConfig ActionConfig) (map[string]action.Extractor, error) {
+	if len(actionConfig.Outputs) == 0 {
+		return nil, nil
+	}
+	if actionConfig.Id == "" {
+		return nil, fmt.Errorf("newOutputExtractors action %s declares outputs but has no id", actionConfig.Type)
+	}
+	extractors := make(map[string]action.Extractor, len(actionConfig.Outputs))
+	for name, outputConfig := range actionConfig.Outputs {
+		extractor, err := newExtractor(outputConfig)
+		if err != nil {
+			return nil, fmt.Errorf("newOutputExtractors error while creating output %s of action %s: %w", name, actionConfig.Id, err)
+		}
+		extractors[name] = extractor
+	}
+	return extractors, nil
+}
+
+// StepOutputs::Store extract outputs of action with id from its result
+func (s StepOutputs) Store(id string, extractors map[string]action.Extractor, body []byte, header http.Header) error {
+	if len(extractors) == 0 {
+		return nil
+	}
+	outputs := make(map[string]string, len(extractors))
+	for name, extractor := range extractors {
+		value, err := extractor.Extract(body, header)
+		if err != nil {
+			return fmt.Errorf("StepOutputs::Store error while extracting output %s of action %s: %w", name, id, err)
+		}
+		outputs[name] = value
+	}
+	s[id] = outputs
+	return nil
+}
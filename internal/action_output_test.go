@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStepOutputs_Store(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ActionConfig
+		body    string
+		want    StepOutputs
+		wantErr bool
+	}{
+		{
+			name: "Test 1",
+			config: ActionConfig{Id: "release", Outputs: map[string]OutputConfig{
+				"id":      {Type: OutputJsonPath, Path: "data.id"},
+				"version": {Type: OutputRegex, Regex: `v(\d+)`},
+			}},
+			body: `{"data": {"id": 42}, "tag": "v7"}`,
+			want: StepOutputs{"release": {"id": "42", "version": "7"}},
+		},
+		{
+			name:    "Test 2",
+			config:  ActionConfig{Outputs: map[string]OutputConfig{"id": {Type: OutputStdout}}},
+			wantErr: true,
+		},
+		{
+			name:    "Test 3",
+			config:  ActionConfig{Id: "release", Outputs: map[string]OutputConfig{"id": {Type: OutputRegex, Regex: "(["}}},
+			wantErr: true,
+		},
+		{
+			name:   "Test 4",
+			config: ActionConfig{},
+			want:   StepOutputs{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// invalid outputs are reported before action is run
+			extractors, err := newOutputExtractors(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newOutputExtractors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := make(StepOutputs)
+			if err := got.Store(tt.config.Id, extractors, []byte(tt.body), nil); err != nil {
+				t.Fatalf("StepOutputs::Store() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StepOutputs::Store() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
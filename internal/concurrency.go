@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"github.com/bednarradek/php-deployer/pkg/ftp"
+)
+
+const (
+	defaultHashWorkers     = 10
+	defaultListWorkers     = 5
+	defaultTransferWorkers = 10
+)
+
+// ConcurrencyConfig::HashWorkers return number of files hashed in parallel
+func (c ConcurrencyConfig) HashWorkers() int {
+	return workers(c.Hash, defaultHashWorkers)
+}
+
+// ConcurrencyConfig::ListWorkers return number of remote directories listed in parallel
+func (c ConcurrencyConfig) ListWorkers() int {
+	return workers(c.List, defaultListWorkers)
+}
+
+// ConcurrencyConfig::TransferWorkers return number of files uploaded, deleted or backed up in parallel
+func (c ConcurrencyConfig) TransferWorkers() int {
+	return workers(c.Transfer, defaultTransferWorkers)
+}
+
+// ConcurrencyConfig::PoolOptions return options of FTP connection pool
+func (c ConcurrencyConfig) PoolOptions() ftp.PoolOptions {
+	return ftp.PoolOptions{
+		InitialCap: c.PoolInitial,
+		MaxCap:     c.PoolMax,
+		MaxIdle:    c.PoolIdle,
+		Adaptive:   c.Adaptive,
+	}
+}
+
+func workers(value int, def int) int {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
@@ -2,20 +2,43 @@ package internal
 
 const EnvironmentGenerator = "environment_generator"
 const HttpAction = "http_action"
+const CommandAction = "command_action"
 
 type GeneratorConfig struct {
 	Type      string            `json:"type"`
 	Arguments map[string]string `json:"arguments"`
 }
 
+type EnvironmentGeneratorArguments struct {
+	TemplatePath string `json:"templatePath"`
+	Destination  string `json:"destination"`
+	Escape       string `json:"escape,omitempty"`
+}
+
 type MoveConfig struct {
 	Source      string `json:"source"`
 	Destination string `json:"destination"`
 }
 
 type ActionConfig struct {
-	Type      string                 `json:"type"`
-	Arguments map[string]interface{} `json:"Arguments"`
+	Id        string                  `json:"id,omitempty"`
+	Type      string                  `json:"type"`
+	Arguments map[string]interface{}  `json:"arguments"`
+	Outputs   map[string]OutputConfig `json:"outputs,omitempty"`
+}
+
+const (
+	OutputJsonPath = "json_path"
+	OutputRegex    = "regex"
+	OutputHeader   = "header"
+	OutputStdout   = "stdout"
+)
+
+type OutputConfig struct {
+	Type  string `json:"type"`
+	Path  string `json:"path,omitempty"`
+	Regex string `json:"regex,omitempty"`
+	Name  string `json:"name,omitempty"`
 }
 
 type CleanConfig struct {
@@ -30,24 +53,124 @@ type StepConfig struct {
 	Clean    CleanConfig       `json:"clean,omitempty"`
 }
 
+// PriorityConfig upload phase of files matched by gitignore patterns, phase without patterns contains all unmatched files
+type PriorityConfig struct {
+	Name     string   `json:"name"`
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// ConcurrencyConfig number of parallel operations and sizes of FTP connection pool, zero value means default
+type ConcurrencyConfig struct {
+	Hash        int  `json:"hash,omitempty"`
+	List        int  `json:"list,omitempty"`
+	Transfer    int  `json:"transfer,omitempty"`
+	PoolInitial int  `json:"pool_initial,omitempty"`
+	PoolMax     int  `json:"pool_max,omitempty"`
+	PoolIdle    int  `json:"pool_idle,omitempty"`
+	Adaptive    bool `json:"adaptive,omitempty"`
+}
+
 type FtpSyncConfig struct {
-	Source          string   `json:"source"`
-	Destination     string   `json:"destination"`
-	LogFileDest     string   `json:"log_file_dest"`
-	IgnoreList      []string `json:"ignore_list"`
-	DefaultFileMode string   `json:"default_file_mode"`
-	DefaultDirMode  string   `json:"default_dir_mode"`
-	FtpConfig       struct {
+	Source                  string            `json:"source"`
+	Destination             string            `json:"destination"`
+	LogFileDest             string            `json:"log_file_dest"`
+	IgnoreList              []string          `json:"ignore_list"`
+	UseGitignore            bool              `json:"use_gitignore,omitempty"`
+	Protect                 []string          `json:"protect,omitempty"`
+	DeletePolicy            string            `json:"delete_policy,omitempty"`
+	MaxDeletions            string            `json:"max_deletions,omitempty"`
+	Priority                []PriorityConfig  `json:"priority,omitempty"`
+	HashCacheFile           string            `json:"hash_cache_file,omitempty"`
+	HashAlgorithm           string            `json:"hash_algorithm,omitempty"`
+	Concurrency             ConcurrencyConfig `json:"concurrency,omitempty"`
+	ContinueOnError         bool              `json:"continue_on_error,omitempty"`
+	MaxUploadRate           string            `json:"max_upload_rate,omitempty"`
+	MaxConnectionUploadRate string            `json:"max_connection_upload_rate,omitempty"`
+	DefaultFileMode         string            `json:"default_file_mode"`
+	DefaultDirMode          string            `json:"default_dir_mode"`
+	FtpConfig               struct {
 		Host     string `json:"host"`
 		User     string `json:"user"`
 		Password string `json:"password"`
 	} `json:"ftp_config"`
 }
 
+type HealthCheckConfig struct {
+	Action    ActionConfig `json:"action"`
+	Rollback  bool         `json:"rollback,omitempty"`
+	BackupDir string       `json:"backup_dir,omitempty"`
+}
+
 type FtpConfig struct {
-	Before          StepConfig    `json:"before,omitempty"`
-	Sync            FtpSyncConfig `json:"sync"`
-	Folders         []string      `json:"folders,omitempty"`
-	ReadableFolders []string      `json:"readable_folders,omitempty"`
-	After           StepConfig    `json:"after,omitempty"`
+	Vars            map[string]string  `json:"vars,omitempty"`
+	EnvFiles        []string           `json:"env_files,omitempty"`
+	Secrets         map[string]string  `json:"secrets,omitempty"`
+	SecretsFile     string             `json:"secrets_file,omitempty"`
+	Before          StepConfig         `json:"before,omitempty"`
+	Sync            FtpSyncConfig      `json:"sync"`
+	Folders         []string           `json:"folders,omitempty"`
+	ReadableFolders []string           `json:"readable_folders,omitempty"`
+	After           StepConfig         `json:"after,omitempty"`
+	HealthCheck     *HealthCheckConfig `json:"health_check,omitempty"`
+}
+
+type HttpActionArguments struct {
+	Url            string                `json:"url"`
+	Method         string                `json:"method"`
+	Headers        map[string]string     `json:"headers,omitempty"`
+	Body           string                `json:"body,omitempty"`
+	Timeout        string                `json:"timeout,omitempty"`
+	Retry          HttpRetryConfig       `json:"retry,omitempty"`
+	Poll           HttpPollConfig        `json:"poll,omitempty"`
+	ExpectedStatus []int                 `json:"expected_status,omitempty"`
+	Assert         []HttpAssertionConfig `json:"assert,omitempty"`
+	Auth           HttpAuthConfig        `json:"auth,omitempty"`
+	Tls            HttpTlsConfig         `json:"tls,omitempty"`
+}
+
+type CommandActionArguments struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Dir     string   `json:"dir,omitempty"`
+}
+
+type HttpRetryConfig struct {
+	Attempts int    `json:"attempts,omitempty"`
+	Delay    string `json:"delay,omitempty"`
+}
+
+type HttpPollConfig struct {
+	Timeout  string `json:"timeout,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+const (
+	HttpAssertionContains = "contains"
+	HttpAssertionRegex    = "regex"
+	HttpAssertionJsonPath = "json_path"
+)
+
+type HttpAssertionConfig struct {
+	Type  string `json:"type"`
+	Path  string `json:"path,omitempty"`
+	Value string `json:"value"`
+}
+
+const (
+	HttpAuthBasic  = "basic"
+	HttpAuthBearer = "bearer"
+)
+
+type HttpAuthConfig struct {
+	Type     string `json:"type,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+type HttpTlsConfig struct {
+	CaFile   string `json:"ca_file,omitempty"`
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+	Insecure bool   `json:"insecure,omitempty"`
 }
@@ -0,0 +1,307 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConfigFormatJson = "json"
+	ConfigFormatYaml = "yaml"
+	ConfigFormatToml = "toml"
+)
+
+var yamlLineRegex = regexp.MustCompile(`line (\d+)`)
+
+// ConfigError error of config file with position, line and column are zero when unknown
+type ConfigError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// DetectConfigFormat return format by extension of path, fallback is used for unknown extensions
+func DetectConfigFormat(path string, fallback string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ConfigFormatJson, nil
+	case ".yaml", ".yml":
+		return ConfigFormatYaml, nil
+	case ".toml":
+		return ConfigFormatToml, nil
+	}
+	if fallback == "" {
+		return ConfigFormatJson, nil
+	}
+	switch fallback {
+	case ConfigFormatJson, ConfigFormatYaml, ConfigFormatToml:
+		return fallback, nil
+	case "yml":
+		return ConfigFormatYaml, nil
+	}
+	return "", fmt.Errorf("DetectConfigFormat unknown config format: %s", fallback)
+}
+
+// parseConfig parse config file content to generic document
+func parseConfig(path string, format string, content []byte) (map[string]any, error) {
+	var document map[string]any
+	switch format {
+	case ConfigFormatJson:
+		if err := json.Unmarshal(content, &document); err != nil {
+			return nil, jsonConfigError(path, content, err)
+		}
+		return document, nil
+	case ConfigFormatYaml:
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return nil, yamlConfigError(path, err)
+		}
+	case ConfigFormatToml:
+		if err := toml.Unmarshal(content, &document); err != nil {
+			return nil, tomlConfigError(path, err)
+		}
+	default:
+		return nil, fmt.Errorf("parseConfig unknown config format: %s", format)
+	}
+	if document == nil {
+		document = make(map[string]any)
+	}
+	// normalize values to JSON types
+	b, err := json.Marshal(document)
+	if err != nil {
+		return nil, &ConfigError{Path: path, Err: err}
+	}
+	normalized := make(map[string]any)
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return nil, &ConfigError{Path: path, Err: err}
+	}
+	return normalized, nil
+}
+
+// checkConfigTypes decode document to config structure and report type errors with position in original content,
+// prefix is path of document in content
+func checkConfigTypes(path string, format string, content []byte, document map[string]any, prefix ...string) error {
+	b, err := json.Marshal(document)
+	if err != nil {
+		return &ConfigError{Path: path, Err: err}
+	}
+	if unknown := findUnknownFields(document, reflect.TypeOf(FtpConfig{}), nil); len(unknown) > 0 {
+		line, column := locateConfigField(format, content, strings.Join(append(prefix, unknown[0]), "."))
+		return &ConfigError{Path: path, Line: line, Column: column, Err: fmt.Errorf("unknown field %s", unknown[0])}
+	}
+	err = json.Unmarshal(b, new(FtpConfig))
+	if err == nil {
+		return nil
+	}
+	var typeError *json.UnmarshalTypeError
+	if !errors.As(err, &typeError) {
+		return &ConfigError{Path: path, Err: err}
+	}
+	line, column := locateConfigField(format, content, strings.Join(append(prefix, typeError.Field), "."))
+	return &ConfigError{
+		Path:   path,
+		Line:   line,
+		Column: column,
+		Err:    fmt.Errorf("field %s must be %s, got %s", typeError.Field, typeError.Type.String(), typeError.Value),
+	}
+}
+
+// findUnknownFields return dotted paths of all document keys which do not exist in type t,
+// keys are matched case-insensitively as encoding/json does
+func findUnknownFields(document any, t reflect.Type, path []string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	res := make([]string, 0)
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := document.(map[string]any)
+		if !ok {
+			return res
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field, ok := findJsonField(t, key)
+			if !ok {
+				res = append(res, strings.Join(append(path, key), "."))
+				continue
+			}
+			res = append(res, findUnknownFields(object[key], field.Type, append(path, key))...)
+		}
+	case reflect.Map:
+		object, ok := document.(map[string]any)
+		if !ok {
+			return res
+		}
+		for key, value := range object {
+			res = append(res, findUnknownFields(value, t.Elem(), append(path, key))...)
+		}
+		sort.Strings(res)
+	case reflect.Slice:
+		list, ok := document.([]any)
+		if !ok {
+			return res
+		}
+		for _, value := range list {
+			res = append(res, findUnknownFields(value, t.Elem(), path)...)
+		}
+	}
+	return res
+}
+
+func findJsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if strings.EqualFold(jsonFieldName(field), key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// locateConfigField find line and column of dotted field path (list indexes are skipped) in config content
+func locateConfigField(format string, content []byte, field string) (int, int) {
+	keys := strings.Split(field, ".")
+	if format == ConfigFormatToml {
+		return locateTomlKey(content, keys[len(keys)-1])
+	}
+	// YAML is superset of JSON, so both are located in YAML node tree
+	node := new(yaml.Node)
+	if err := yaml.Unmarshal(content, node); err != nil {
+		return 0, 0
+	}
+	if found := findYamlNode(node, keys); found != nil {
+		return found.Line, found.Column
+	}
+	return 0, 0
+}
+
+func findYamlNode(node *yaml.Node, keys []string) *yaml.Node {
+	if len(keys) == 0 {
+		return node
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if found := findYamlNode(child, keys); found != nil {
+				return found
+			}
+		}
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			if found := findYamlNode(child, keys); found != nil {
+				return found
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value != keys[0] {
+				continue
+			}
+			if len(keys) == 1 {
+				return node.Content[i+1]
+			}
+			if found := findYamlNode(node.Content[i+1], keys[1:]); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+func locateTomlKey(content []byte, key string) (int, int) {
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " \t")
+		name, _, ok := bytes.Cut(trimmed, []byte("="))
+		if ok && string(bytes.Trim(bytes.TrimSpace(name), `"'`)) == key {
+			return i + 1, len(line) - len(trimmed) + 1
+		}
+	}
+	return 0, 0
+}
+
+func jsonConfigError(path string, content []byte, err error) error {
+	var offset int64 = -1
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &syntaxError) {
+		// offset points after the invalid character
+		offset = syntaxError.Offset - 1
+	} else if errors.As(err, &typeError) {
+		offset = typeError.Offset
+	}
+	if offset < 0 {
+		return &ConfigError{Path: path, Err: err}
+	}
+	line, column := 1, 1
+	for _, c := range content[:min(int(offset), len(content))] {
+		if c == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return &ConfigError{Path: path, Line: line, Column: column, Err: err}
+}
+
+func yamlConfigError(path string, err error) error {
+	// yaml reports only line of syntax errors
+	line := 0
+	if match := yamlLineRegex.FindStringSubmatch(err.Error()); match != nil {
+		_, _ = fmt.Sscanf(match[1], "%d", &line)
+	}
+	return &ConfigError{Path: path, Line: line, Err: err}
+}
+
+func tomlConfigError(path string, err error) error {
+	var parseError toml.ParseError
+	if errors.As(err, &parseError) {
+		return &ConfigError{
+			Path:   path,
+			Line:   parseError.Position.Line,
+			Column: parseError.Position.Col,
+			Err:    errors.New(parseError.Message),
+		}
+	}
+	return &ConfigError{Path: path, Err: err}
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+const configExtendsKey = "extends"
+const configProfilesKey = "profiles"
+
+// ConfigLoader load config with inheritance and profiles
+//   - "extends" is path to base config (relative to the config), config is deep merged over its base
+//   - "profiles" are named overrides, selected profile is deep merged over the whole merged config,
+//     so it wins over fields of extending configs, profile of extending config wins over profile of its base
+//   - format of every file is detected by extension, format is used for files with unknown extension
+type ConfigLoader struct {
+	reader file_system.Reader
+	format string
+}
+
+func NewConfigLoader(reader file_system.Reader, format string) *ConfigLoader {
+	return &ConfigLoader{reader: reader, format: format}
+}
+
+func (l *ConfigLoader) Load(ctx context.Context, path string, profile string) (*FtpConfig, error) {
+	overrides := make([]map[string]any, 0)
+	document, err := l.loadDocument(ctx, path, profile, &overrides, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	if profile != "" && len(overrides) == 0 {
+		return nil, fmt.Errorf("ConfigLoader::Load profile %s is not defined in config %s", profile, path)
+	}
+	for _, override := range overrides {
+		document = helpers.DeepMerge(document, override)
+	}
+	config := new(FtpConfig)
+	if err := decodeArguments(document, config); err != nil {
+		return nil, fmt.Errorf("ConfigLoader::Load error while decoding config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ConfigLoader::loadDocument return config merged over its bases, selected profiles are collected into overrides
+// (bases first) and applied by Load
+func (l *ConfigLoader) loadDocument(ctx context.Context, path string, profile string, overrides *[]map[string]any, visited map[string]bool) (map[string]any, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument error while resolving path %s: %w", path, err)
+	}
+	if visited[absPath] {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument config %s extends itself", path)
+	}
+	visited[absPath] = true
+
+	// explicit format is used for the loaded config, extended configs are detected by extension
+	formatPath := absPath
+	if len(visited) == 1 && l.format != "" {
+		formatPath = ""
+	}
+	format, err := DetectConfigFormat(formatPath, l.format)
+	if err != nil {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument error while detecting format of config %s: %w", path, err)
+	}
+	content, err := l.reader.Read(ctx, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument error while reading config %s: %w", path, err)
+	}
+	document, err := parseConfig(path, format, content)
+	if err != nil {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument error while parsing config: %w", err)
+	}
+
+	base := make(map[string]any)
+	if extends, ok := document[configExtendsKey]; ok {
+		extendsPath, ok := extends.(string)
+		if !ok {
+			return nil, fmt.Errorf("ConfigLoader::loadDocument %s of config %s must be a string", configExtendsKey, path)
+		}
+		if !filepath.IsAbs(extendsPath) {
+			extendsPath = filepath.Join(filepath.Dir(absPath), extendsPath)
+		}
+		if base, err = l.loadDocument(ctx, extendsPath, profile, overrides, visited); err != nil {
+			return nil, err
+		}
+	}
+
+	profiles, hasProfiles := document[configProfilesKey]
+	delete(document, configExtendsKey)
+	delete(document, configProfilesKey)
+	if err := checkConfigTypes(path, format, content, helpers.DeepMerge(map[string]any{}, document)); err != nil {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument invalid config: %w", err)
+	}
+	document = helpers.DeepMerge(base, document)
+
+	if profile == "" || !hasProfiles {
+		return document, nil
+	}
+	profilesMap, ok := profiles.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument %s of config %s must be an object", configProfilesKey, path)
+	}
+	override, ok := profilesMap[profile]
+	if !ok {
+		return document, nil
+	}
+	overrideMap, ok := override.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument profile %s of config %s must be an object", profile, path)
+	}
+	if err := checkConfigTypes(path, format, content, helpers.DeepMerge(map[string]any{}, overrideMap), configProfilesKey, profile); err != nil {
+		return nil, fmt.Errorf("ConfigLoader::loadDocument invalid profile %s: %w", profile, err)
+	}
+	*overrides = append(*overrides, overrideMap)
+	return document, nil
+}
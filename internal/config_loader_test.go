@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+)
+
+func TestConfigLoader_Load(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.json": `{
+			"sync": {"source": "/app", "destination": "/www", "ignore_list": ["^/.git"]},
+			"after": {"action": [{"type": "http_action", "arguments": {"url": "base"}}]},
+			"profiles": {"production": {"sync": {"log_file_dest": "/log/production", "destination": "/base", "ftp_config": {"user": "production"}}}}
+		}`,
+		"config.json": `{
+			"extends": "base.json",
+			"sync": {"ftp_config": {"host": "default", "user": "default"}},
+			"profiles": {
+				"staging": {"sync": {"destination": "/staging"}},
+				"production": {
+					"sync": {"destination": "/production", "ftp_config": {"host": "production"}},
+					"after": {"action+": [{"type": "http_action", "arguments": {"url": "production"}}]}
+				}
+			}
+		}`,
+		"loop.json": `{"extends": "loop.json"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		profile     string
+		destination string
+		host        string
+		user        string
+		logFile     string
+		actions     int
+		wantErr     bool
+	}{
+		{
+			name:        "Test 1",
+			path:        "config.json",
+			destination: "/www",
+			host:        "default",
+			user:        "default",
+			actions:     1,
+		},
+		{
+			name:        "Test 2",
+			path:        "config.json",
+			profile:     "staging",
+			destination: "/staging",
+			host:        "default",
+			user:        "default",
+			actions:     1,
+		},
+		// profile of base wins over extending config, profile of extending config wins over profile of base
+		{
+			name:        "Test 3",
+			path:        "config.json",
+			profile:     "production",
+			destination: "/production",
+			host:        "production",
+			user:        "production",
+			logFile:     "/log/production",
+			actions:     2,
+		},
+		{
+			name:    "Test 4",
+			path:    "config.json",
+			profile: "unknown",
+			wantErr: true,
+		},
+		{
+			name:    "Test 5",
+			path:    "loop.json",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewConfigLoader(file_system.NewSystemReader(), "").Load(context.Background(), filepath.Join(dir, tt.path), tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if config.Sync.Source != "/app" {
+				t.Errorf("Load() source = %v, want %v", config.Sync.Source, "/app")
+			}
+			if config.Sync.Destination != tt.destination {
+				t.Errorf("Load() destination = %v, want %v", config.Sync.Destination, tt.destination)
+			}
+			if config.Sync.FtpConfig.Host != tt.host {
+				t.Errorf("Load() host = %v, want %v", config.Sync.FtpConfig.Host, tt.host)
+			}
+			if config.Sync.FtpConfig.User != tt.user {
+				t.Errorf("Load() user = %v, want %v", config.Sync.FtpConfig.User, tt.user)
+			}
+			if config.Sync.LogFileDest != tt.logFile {
+				t.Errorf("Load() log file = %v, want %v", config.Sync.LogFileDest, tt.logFile)
+			}
+			if len(config.After.Action) != tt.actions {
+				t.Errorf("Load() actions = %v, want %v", len(config.After.Action), tt.actions)
+			}
+		})
+	}
+}
+
+func TestConfigLoader_LoadFormats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.toml":    "[sync]\nsource = \"/app\"\ndestination = \"/www\"\n\n[sync.ftp_config]\nhost = \"toml\"\n",
+		"config.yaml":  "# yaml config\nextends: base.toml\nafter:\n  action:\n    - type: http_action\n      arguments:\n        url: https://example.com\n        body: '{\"example\": \"value\"}'\n",
+		"config.conf":  "sync:\n  destination: /conf\n",
+		"invalid.json": "{\n  \"sync\": {\n    \"source\": \"/app\",,\n  }\n}",
+		"invalid.yaml": "sync:\n  source: /app\n  destination: [\n",
+		"invalid.toml": "[sync]\nsource = \n",
+		"type.json":    "{\n  \"sync\": {\n    \"ignore_list\": \"regex\"\n  }\n}",
+		"type.yaml":    "sync:\n  ftp_config:\n    host:\n      - a\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		format      string
+		destination string
+		wantErr     string
+	}{
+		{
+			name:        "Test 1",
+			path:        "config.yaml",
+			destination: "/www",
+		},
+		{
+			name:        "Test 2",
+			path:        "config.conf",
+			format:      "yaml",
+			destination: "/conf",
+		},
+		{
+			name:    "Test 3",
+			path:    "invalid.json",
+			wantErr: "invalid.json:3:22:",
+		},
+		{
+			name:    "Test 4",
+			path:    "invalid.yaml",
+			wantErr: "invalid.yaml:3:",
+		},
+		{
+			name:    "Test 5",
+			path:    "invalid.toml",
+			wantErr: "invalid.toml:2:",
+		},
+		{
+			name:    "Test 6",
+			path:    "type.json",
+			wantErr: "type.json:3:20: field sync.ignore_list",
+		},
+		{
+			name:    "Test 7",
+			path:    "type.yaml",
+			wantErr: "type.yaml:4:7: field sync.ftp_config.host",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.path)
+			config, err := NewConfigLoader(file_system.NewSystemReader(), tt.format).Load(context.Background(), path, "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, tt.wantErr)) {
+					t.Fatalf("Load() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if config.Sync.Destination != tt.destination {
+				t.Errorf("Load() destination = %v, want %v", config.Sync.Destination, tt.destination)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const schemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// ConfigSchema return JSON Schema of config for editor autocompletion
+func ConfigSchema() map[string]any {
+	schema := schemaOf(reflect.TypeOf(FtpConfig{}))
+	schema["$schema"] = schemaVersion
+	schema["title"] = "Deployer config"
+	properties := schema["properties"].(map[string]any)
+	properties[configExtendsKey] = map[string]any{
+		"type":        "string",
+		"description": "Path to base config",
+	}
+	properties[configProfilesKey] = map[string]any{
+		"type":                 "object",
+		"description":          "Named overrides selected by --env flag",
+		"additionalProperties": map[string]any{"type": "object"},
+	}
+	return schema
+}
+
+func schemaOf(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Struct:
+		switch t {
+		case reflect.TypeOf(GeneratorConfig{}):
+			return typedSchema(t, generatorArguments)
+		case reflect.TypeOf(ActionConfig{}):
+			return typedSchema(t, actionArguments)
+		}
+		properties := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || jsonFieldName(field) == "-" {
+				continue
+			}
+			properties[jsonFieldName(field)] = schemaOf(field.Type)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaOf(t.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": schemaOf(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+// typedSchema schema of config with type and arguments depending on the type
+func typedSchema(t reflect.Type, arguments map[string]reflect.Type) map[string]any {
+	schema := schemaOf(reflect.StructOf(fieldsWithoutArguments(t)))
+	types := make([]string, 0, len(arguments))
+	for name := range arguments {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+
+	properties := schema["properties"].(map[string]any)
+	properties["type"] = map[string]any{"enum": types}
+	properties["arguments"] = map[string]any{"type": "object"}
+
+	conditions := make([]any, 0, len(types))
+	for _, name := range types {
+		conditions = append(conditions, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{"type": map[string]any{"const": name}},
+			},
+			"then": map[string]any{
+				"properties": map[string]any{"arguments": schemaOf(arguments[name])},
+			},
+		})
+	}
+	schema["allOf"] = conditions
+	schema["required"] = []string{"type"}
+	return schema
+}
+
+func fieldsWithoutArguments(t reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if strings.EqualFold(jsonFieldName(t.Field(i)), "arguments") {
+			continue
+		}
+		fields = append(fields, t.Field(i))
+	}
+	return fields
+}
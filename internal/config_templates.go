@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+)
+
+// ConfigTemplate template used by config with its location
+type ConfigTemplate struct {
+	Location string
+	Content  string
+}
+
+// collectTemplates return all templates of config - ftp config, action arguments and template files of generators
+func collectTemplates(ctx context.Context, reader file_system.Reader, config *FtpConfig) ([]ConfigTemplate, []error) {
+	templates := make([]ConfigTemplate, 0, 20)
+	errs := make([]error, 0)
+	add := func(location string, content string) {
+		if content != "" {
+			templates = append(templates, ConfigTemplate{Location: location, Content: content})
+		}
+	}
+
+	add("sync.ftp_config.host", config.Sync.FtpConfig.Host)
+	add("sync.ftp_config.user", config.Sync.FtpConfig.User)
+	add("sync.ftp_config.password", config.Sync.FtpConfig.Password)
+
+	addAction := func(path string, actionConfig ActionConfig) {
+		switch actionConfig.Type {
+		case HttpAction:
+			args := new(HttpActionArguments)
+			if err := decodeArguments(actionConfig.Arguments, args); err != nil {
+				errs = append(errs, fmt.Errorf("%s.arguments: %w", path, err))
+				return
+			}
+			add(path+".arguments.url", args.Url)
+			add(path+".arguments.method", args.Method)
+			add(path+".arguments.body", args.Body)
+			for k, h := range args.Headers {
+				add(fmt.Sprintf("%s.arguments.headers.%s", path, k), h)
+			}
+			for i, a := range args.Assert {
+				add(fmt.Sprintf("%s.arguments.assert[%d].value", path, i), a.Value)
+			}
+			add(path+".arguments.auth.user", args.Auth.User)
+			add(path+".arguments.auth.password", args.Auth.Password)
+			add(path+".arguments.auth.token", args.Auth.Token)
+		case CommandAction:
+			args := new(CommandActionArguments)
+			if err := decodeArguments(actionConfig.Arguments, args); err != nil {
+				errs = append(errs, fmt.Errorf("%s.arguments: %w", path, err))
+				return
+			}
+			add(path+".arguments.command", args.Command)
+			for i, a := range args.Args {
+				add(fmt.Sprintf("%s.arguments.args[%d]", path, i), a)
+			}
+			add(path+".arguments.dir", args.Dir)
+		}
+	}
+
+	steps := []struct {
+		name string
+		step StepConfig
+	}{{"before", config.Before}, {"after", config.After}}
+	for _, s := range steps {
+		for i, g := range s.step.Generate {
+			path := fmt.Sprintf("%s.generate[%d].arguments.templatePath", s.name, i)
+			templatePath, ok := g.Arguments["templatePath"]
+			if g.Type != EnvironmentGenerator || !ok || templatePath == "" {
+				continue
+			}
+			content, err := reader.Read(ctx, fmt.Sprintf("%s%s", config.Sync.Source, templatePath))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
+			}
+			add(fmt.Sprintf("%s (%s)", path, templatePath), string(content))
+		}
+		for i, a := range s.step.Action {
+			addAction(fmt.Sprintf("%s.action[%d]", s.name, i), a)
+		}
+	}
+	if config.HealthCheck != nil {
+		addAction("health_check.action", config.HealthCheck.Action)
+	}
+	return templates, errs
+}
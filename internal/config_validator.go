@@ -0,0 +1,295 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/filter"
+	"github.com/bednarradek/php-deployer/pkg/generator"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+	"github.com/bednarradek/php-deployer/pkg/throttle"
+)
+
+// argument types of generators and actions by their type
+var generatorArguments = map[string]reflect.Type{
+	EnvironmentGenerator: reflect.TypeOf(EnvironmentGeneratorArguments{}),
+}
+
+var actionArguments = map[string]reflect.Type{
+	HttpAction:    reflect.TypeOf(HttpActionArguments{}),
+	CommandAction: reflect.TypeOf(CommandActionArguments{}),
+}
+
+// ConfigValidator check config before deploy, all problems are collected
+type ConfigValidator struct {
+	reader    file_system.Reader
+	generator *generator.EnvironmentGenerator
+	errors    []error
+	actionIds map[string]bool
+}
+
+func NewConfigValidator(reader file_system.Reader) *ConfigValidator {
+	g := generator.NewEnvironmentGenerator()
+	// functions registered during deploy, only their names matter for parsing
+	g.AddFunction("secret", func(string) (string, error) { return "", nil })
+	return &ConfigValidator{reader: reader, generator: g}
+}
+
+func (v *ConfigValidator) Validate(ctx context.Context, config *FtpConfig) []error {
+	v.errors = make([]error, 0)
+	v.actionIds = make(map[string]bool)
+
+	v.validateSync(config.Sync)
+	if patterns, err := readIgnorePatterns(ctx, v.reader, config.Sync); err != nil {
+		v.fail("sync", err)
+	} else if _, err := filter.NewGitIgnoreFilter(patterns); err != nil {
+		v.fail("sync", err)
+	}
+	v.validateStep("before", config.Before)
+	v.validateStep("after", config.After)
+	if config.HealthCheck != nil {
+		v.validateAction("health_check.action", config.HealthCheck.Action)
+	}
+	for name, value := range config.Vars {
+		if name == "" {
+			v.fail("vars", fmt.Errorf("variable name %q must not be empty", value))
+		}
+	}
+
+	templates, errs := collectTemplates(ctx, v.reader, config)
+	v.errors = append(v.errors, errs...)
+	for _, t := range templates {
+		if err := v.generator.Parse([]byte(t.Content)); err != nil {
+			v.fail(t.Location, err)
+		}
+	}
+	return v.errors
+}
+
+func (v *ConfigValidator) fail(path string, err error) {
+	v.errors = append(v.errors, fmt.Errorf("%s: %w", path, err))
+}
+
+func (v *ConfigValidator) required(path string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.fail(path, fmt.Errorf("value is required"))
+	}
+}
+
+func (v *ConfigValidator) duration(path string, value string) {
+	if _, err := parseDuration(value); err != nil {
+		v.fail(path, err)
+	}
+}
+
+func (v *ConfigValidator) mode(path string, value string) {
+	if _, err := strconv.ParseUint(value, 8, 32); err != nil {
+		v.fail(path, fmt.Errorf("%q is not an octal mode", value))
+	}
+}
+
+func (v *ConfigValidator) validateSync(sync FtpSyncConfig) {
+	v.required("sync.source", sync.Source)
+	v.required("sync.destination", sync.Destination)
+	if !strings.HasPrefix(sync.LogFileDest, "/") {
+		v.fail("sync.log_file_dest", fmt.Errorf("%q must be an absolute path", sync.LogFileDest))
+	}
+	v.mode("sync.default_file_mode", sync.DefaultFileMode)
+	v.mode("sync.default_dir_mode", sync.DefaultDirMode)
+	for i, ignore := range sync.IgnoreList {
+		if _, err := regexp.Compile(ignore); err != nil {
+			v.fail(fmt.Sprintf("sync.ignore_list[%d]", i), err)
+		}
+	}
+	if sync.HashAlgorithm != "" && !helpers.IsHashAlgorithm(sync.HashAlgorithm) {
+		v.fail("sync.hash_algorithm", fmt.Errorf("unknown hash algorithm %q", sync.HashAlgorithm))
+	}
+	v.validateConcurrency(sync.Concurrency)
+	if _, err := throttle.ParseRate(sync.MaxUploadRate); err != nil {
+		v.fail("sync.max_upload_rate", err)
+	}
+	if _, err := throttle.ParseRate(sync.MaxConnectionUploadRate); err != nil {
+		v.fail("sync.max_connection_upload_rate", err)
+	}
+	switch sync.DeletePolicy {
+	case "", DeletePolicyMirror, DeletePolicyOwned, DeletePolicyNone:
+	default:
+		v.fail("sync.delete_policy", fmt.Errorf("unknown delete policy %q", sync.DeletePolicy))
+	}
+	if sync.MaxDeletions != "" {
+		if _, err := parseMaxDeletions(sync.MaxDeletions, 0); err != nil {
+			v.fail("sync.max_deletions", err)
+		}
+	}
+	priorities := make(map[string]bool)
+	for i, p := range sync.Priority {
+		path := fmt.Sprintf("sync.priority[%d]", i)
+		v.required(path+".name", p.Name)
+		if priorities[p.Name] {
+			v.fail(path+".name", fmt.Errorf("duplicate phase name %q", p.Name))
+		}
+		priorities[p.Name] = true
+		if _, err := filter.NewGitIgnoreFilter(p.Patterns); err != nil {
+			v.fail(path+".patterns", err)
+		}
+	}
+	if _, err := filter.NewGitIgnoreFilter(sync.Protect); err != nil {
+		v.fail("sync.protect", err)
+	}
+	v.required("sync.ftp_config.host", sync.FtpConfig.Host)
+}
+
+func (v *ConfigValidator) validateConcurrency(c ConcurrencyConfig) {
+	values := []struct {
+		name  string
+		value int
+	}{
+		{"hash", c.Hash},
+		{"list", c.List},
+		{"transfer", c.Transfer},
+		{"pool_initial", c.PoolInitial},
+		{"pool_max", c.PoolMax},
+		{"pool_idle", c.PoolIdle},
+	}
+	for _, i := range values {
+		if i.value < 0 {
+			v.fail("sync.concurrency."+i.name, fmt.Errorf("must not be negative, got %d", i.value))
+		}
+	}
+	if c.PoolMax > 0 && c.PoolInitial > c.PoolMax {
+		v.fail("sync.concurrency.pool_initial", fmt.Errorf("%d is greater than pool_max %d", c.PoolInitial, c.PoolMax))
+	}
+	if c.PoolMax > 0 && c.PoolIdle > c.PoolMax {
+		v.fail("sync.concurrency.pool_idle", fmt.Errorf("%d is greater than pool_max %d", c.PoolIdle, c.PoolMax))
+	}
+}
+
+func (v *ConfigValidator) validateStep(path string, step StepConfig) {
+	for i, g := range step.Generate {
+		v.validateGenerator(fmt.Sprintf("%s.generate[%d]", path, i), g)
+	}
+	for i, m := range step.Move {
+		v.required(fmt.Sprintf("%s.move[%d].source", path, i), m.Source)
+		v.required(fmt.Sprintf("%s.move[%d].destination", path, i), m.Destination)
+	}
+	for i, a := range step.Action {
+		v.validateAction(fmt.Sprintf("%s.action[%d]", path, i), a)
+	}
+}
+
+// decodeStrict decode arguments to typed structure, unknown arguments are reported and decoding continues
+func (v *ConfigValidator) decodeStrict(path string, arguments any, out any) bool {
+	b, err := json.Marshal(arguments)
+	if err != nil {
+		v.fail(path, err)
+		return false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err == nil {
+		return true
+	}
+	var document any
+	if err := json.Unmarshal(b, &document); err != nil {
+		v.fail(path, err)
+		return false
+	}
+	for _, unknown := range findUnknownFields(document, reflect.TypeOf(out), nil) {
+		v.fail(path, fmt.Errorf("unknown argument %s", unknown))
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		v.fail(path, err)
+		return false
+	}
+	return true
+}
+
+func (v *ConfigValidator) validateGenerator(path string, generatorConfig GeneratorConfig) {
+	if _, ok := generatorArguments[generatorConfig.Type]; !ok {
+		v.fail(path+".type", fmt.Errorf("unknown generator type %q", generatorConfig.Type))
+		return
+	}
+	args := new(EnvironmentGeneratorArguments)
+	if !v.decodeStrict(path+".arguments", generatorConfig.Arguments, args) {
+		return
+	}
+	v.required(path+".arguments.templatePath", args.TemplatePath)
+	v.required(path+".arguments.destination", args.Destination)
+	if _, err := v.generator.WithEscape(args.Escape); err != nil {
+		v.fail(path+".arguments.escape", err)
+	}
+}
+
+func (v *ConfigValidator) validateAction(path string, actionConfig ActionConfig) {
+	if actionConfig.Id != "" {
+		if v.actionIds[actionConfig.Id] {
+			v.fail(path+".id", fmt.Errorf("duplicate action id %q", actionConfig.Id))
+		}
+		v.actionIds[actionConfig.Id] = true
+	}
+	if len(actionConfig.Outputs) > 0 && actionConfig.Id == "" {
+		v.fail(path+".outputs", fmt.Errorf("action with outputs must have id"))
+	}
+	for name, output := range actionConfig.Outputs {
+		if _, err := newExtractor(output); err != nil {
+			v.fail(fmt.Sprintf("%s.outputs.%s", path, name), err)
+		}
+	}
+
+	switch actionConfig.Type {
+	case HttpAction:
+		args := new(HttpActionArguments)
+		if !v.decodeStrict(path+".arguments", actionConfig.Arguments, args) {
+			return
+		}
+		v.validateHttpAction(path+".arguments", args)
+	case CommandAction:
+		args := new(CommandActionArguments)
+		if !v.decodeStrict(path+".arguments", actionConfig.Arguments, args) {
+			return
+		}
+		v.required(path+".arguments.command", args.Command)
+	default:
+		v.fail(path+".type", fmt.Errorf("unknown action type %q", actionConfig.Type))
+	}
+}
+
+func (v *ConfigValidator) validateHttpAction(path string, args *HttpActionArguments) {
+	v.required(path+".url", args.Url)
+	v.required(path+".method", args.Method)
+	v.duration(path+".timeout", args.Timeout)
+	v.duration(path+".retry.delay", args.Retry.Delay)
+	v.duration(path+".poll.timeout", args.Poll.Timeout)
+	v.duration(path+".poll.interval", args.Poll.Interval)
+	for i, a := range args.Assert {
+		p := fmt.Sprintf("%s.assert[%d]", path, i)
+		switch a.Type {
+		case HttpAssertionContains:
+		case HttpAssertionRegex:
+			if _, err := regexp.Compile(a.Value); err != nil && !strings.Contains(a.Value, "{{") {
+				v.fail(p+".value", err)
+			}
+		case HttpAssertionJsonPath:
+			v.required(p+".path", a.Path)
+		default:
+			v.fail(p+".type", fmt.Errorf("unknown assertion type %q", a.Type))
+		}
+	}
+	switch args.Auth.Type {
+	case "", HttpAuthBasic:
+	case HttpAuthBearer:
+		v.required(path+".auth.token", args.Auth.Token)
+	default:
+		v.fail(path+".auth.type", fmt.Errorf("unknown auth type %q", args.Auth.Type))
+	}
+	if args.Tls.CertFile != "" && args.Tls.KeyFile == "" {
+		v.fail(path+".tls.key_file", fmt.Errorf("key file is required with cert file"))
+	}
+}
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+)
+
+func TestConfigValidator_Validate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "env.tpl"), []byte("A={{.A}}\nB={{.B"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	validSync := FtpSyncConfig{
+		Source:          dir,
+		Destination:     "/www",
+		LogFileDest:     "/log/deploy.json",
+		DefaultFileMode: "775",
+		DefaultDirMode:  "0775",
+	}
+	validSync.FtpConfig.Host = "{{.FTP_HOST}}"
+
+	tests := []struct {
+		name   string
+		config func() *FtpConfig
+		want   []string
+	}{
+		{
+			name: "Test 1",
+			config: func() *FtpConfig {
+				return &FtpConfig{Sync: validSync}
+			},
+			want: nil,
+		},
+		{
+			name: "Test 2",
+			config: func() *FtpConfig {
+				sync := validSync
+				sync.IgnoreList = []string{"^/vendor", "(["}
+				sync.DefaultFileMode = "rwx"
+				return &FtpConfig{Sync: sync}
+			},
+			want: []string{"sync.default_file_mode", "sync.ignore_list[1]"},
+		},
+		{
+			name: "Test 3",
+			config: func() *FtpConfig {
+				return &FtpConfig{
+					Sync: validSync,
+					Before: StepConfig{
+						Generate: []GeneratorConfig{
+							{Type: EnvironmentGenerator, Arguments: map[string]string{"templatePath": "/env.tpl", "destination": "/.env", "typo": "x"}},
+							{Type: "unknown_generator"},
+						},
+					},
+				}
+			},
+			want: []string{"before.generate[0].arguments: unknown argument typo", "before.generate[1].type", "before.generate[0].arguments.templatePath"},
+		},
+		{
+			name: "Test 4",
+			config: func() *FtpConfig {
+				return &FtpConfig{
+					Sync: validSync,
+					After: StepConfig{
+						Action: []ActionConfig{
+							{Type: HttpAction, Arguments: map[string]interface{}{"url": "https://example.com", "poll": map[string]interface{}{"timeout": "1 minute"}}},
+							{Type: CommandAction, Outputs: map[string]OutputConfig{"id": {Type: OutputStdout}}, Arguments: map[string]interface{}{"command": "ls"}},
+						},
+					},
+				}
+			},
+			want: []string{"after.action[0].arguments.method", "after.action[0].arguments.poll.timeout", "after.action[1].outputs"},
+		},
+		{
+			name: "Test 5",
+			config: func() *FtpConfig {
+				sync := validSync
+				sync.Concurrency = ConcurrencyConfig{Transfer: -1, PoolMax: 4, PoolInitial: 5, PoolIdle: 4, Adaptive: true}
+				return &FtpConfig{Sync: sync}
+			},
+			want: []string{"sync.concurrency.transfer", "sync.concurrency.pool_initial"},
+		},
+		{
+			name: "Test 6",
+			config: func() *FtpConfig {
+				sync := validSync
+				sync.MaxUploadRate = "2MB/s"
+				sync.MaxConnectionUploadRate = "2 bananas/s"
+				return &FtpConfig{Sync: sync}
+			},
+			want: []string{"sync.max_connection_upload_rate"},
+		},
+		{
+			name: "Test 7",
+			config: func() *FtpConfig {
+				sync := validSync
+				sync.LogFileDest = "log/deploy.json"
+				return &FtpConfig{Sync: sync}
+			},
+			want: []string{"sync.log_file_dest"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := NewConfigValidator(file_system.NewSystemReader()).Validate(context.Background(), tt.config())
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", errs, tt.want)
+			}
+			for i, err := range errs {
+				if !strings.HasPrefix(err.Error(), tt.want[i]) {
+					t.Errorf("Validate() error %d = %v, want prefix %v", i, err, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,55 +1,99 @@
 package internal
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
-	"github.com/bednarradek/php-deployer/pkg/action"
 	"github.com/bednarradek/php-deployer/pkg/file_system"
 	"github.com/bednarradek/php-deployer/pkg/filter"
 	"github.com/bednarradek/php-deployer/pkg/ftp"
 	"github.com/bednarradek/php-deployer/pkg/generator"
 	"github.com/bednarradek/php-deployer/pkg/helpers"
+	"github.com/bednarradek/php-deployer/pkg/secret"
+	"github.com/bednarradek/php-deployer/pkg/throttle"
 	"github.com/sirupsen/logrus"
 )
 
+// DeployOptions options of deploy given on command line
+type DeployOptions struct {
+	AllowMassDelete bool
+	// Rehash ignore local hash cache and hash all files again
+	Rehash bool
+}
+
 type FtpDeployer struct {
 	config           *FtpConfig
+	configPath       string
+	options          DeployOptions
 	ftpConnection    *ftp.Connection
+	throttle         *throttle.Throttle
 	ftpFactory       *file_system.FtpFactory
 	systemFactory    *file_system.SystemFactory
 	logFactory       *file_system.LogFactory
 	fileSystemFilter filter.Filter
-	envGenerator     generator.Generator
+	protectFilter    filter.Filter
+	uploadPhases     []UploadPhase
+	envGenerator     *generator.EnvironmentGenerator
+	stepOutputs      StepOutputs
+	masker           *secret.Masker
+	syncVariables    map[string]string
+	syncDiff         []CompareResult
+	previousLogFile  *file_system.LogFile
+	backupManager    *BackupManager
+	backupPath       string
+	keepBackup       bool
+	release          string
 }
 
-func NewFtpDeployer(config *FtpConfig) (*FtpDeployer, error) {
-	// connect to ftp
-	logrus.Infof("Connecting to FTP server...")
+func NewFtpDeployer(config *FtpConfig, configPath string, masker *secret.Masker, options DeployOptions) (*FtpDeployer, error) {
+	ctx := context.Background()
 
 	//create env generator
+	stepOutputs := make(StepOutputs)
+	syncVariables := make(map[string]string)
+	setSyncVariables(syncVariables, nil)
+	deployVariables := newDeployVariables(ctx, config, configPath, time.Now())
 	envGenerator := generator.NewEnvironmentGenerator()
-	host, err := envGenerator.Generate(context.Background(), []byte(config.Sync.FtpConfig.Host))
+	if err := loadVariables(ctx, envGenerator, file_system.NewSystemReader(), config, configPath); err != nil {
+		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while loading variables: %w", err)
+	}
+	if err := loadSecrets(ctx, envGenerator, masker, config, configPath); err != nil {
+		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while loading secrets: %w", err)
+	}
+	envGenerator.SetVariable("steps", stepOutputs)
+	envGenerator.SetVariable("sync", syncVariables)
+	envGenerator.SetVariable("deploy", deployVariables)
+
+	// connect to ftp
+	logrus.Infof("Connecting to FTP server...")
+
+	host, err := envGenerator.Generate(ctx, []byte(config.Sync.FtpConfig.Host))
 	if err != nil {
 		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while generating host: %w", err)
 	}
+	deployVariables["host"] = string(host)
 
-	user, err := envGenerator.Generate(context.Background(), []byte(config.Sync.FtpConfig.User))
+	user, err := envGenerator.Generate(ctx, []byte(config.Sync.FtpConfig.User))
 	if err != nil {
 		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while generating user: %w", err)
 	}
 
-	password, err := envGenerator.Generate(context.Background(), []byte(config.Sync.FtpConfig.Password))
+	password, err := envGenerator.Generate(ctx, []byte(config.Sync.FtpConfig.Password))
 	if err != nil {
 		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while generating password: %w", err)
 	}
+	masker.Add(string(password))
 
 	ftpConnection := ftp.NewConnection(
 		string(host),
 		string(user),
 		string(password),
+		config.Sync.Concurrency.PoolOptions(),
 	)
 	if err := ftpConnection.Connect(); err != nil {
 		return nil, fmt.Errorf("FtpDeployer::Deploy error while connecting to ftp: %w", err)
@@ -58,10 +102,18 @@ func NewFtpDeployer(config *FtpConfig) (*FtpDeployer, error) {
 	// create FTP factory
 	logrus.Infof("Preparing all necessary objects...")
 
+	globalRate, connectionRate, err := uploadRates(config.Sync)
+	if err != nil {
+		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while reading upload rates: %w", err)
+	}
+	uploadThrottle := throttle.NewThrottle(globalRate, connectionRate)
+
 	ftpFactory := file_system.NewFtpFactory(
 		ftpConnection,
 		config.Sync.DefaultFileMode,
 		config.Sync.DefaultDirMode,
+		config.Sync.Concurrency.ListWorkers(),
+		uploadThrottle,
 	)
 
 	// create System factory - default mode does not matter in this case
@@ -74,19 +126,50 @@ func NewFtpDeployer(config *FtpConfig) (*FtpDeployer, error) {
 	logFactory := file_system.NewLogFactory(config.Sync.LogFileDest)
 
 	// create filter
-	fileSystemFilter := filter.NewFileSystemFilter(config.Sync.IgnoreList)
+	fileSystemFilter, err := newSyncFilter(ctx, systemFactory.Reader(), config.Sync)
+	if err != nil {
+		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while creating filter: %w", err)
+	}
+	protectFilter, err := filter.NewGitIgnoreFilter(config.Sync.Protect)
+	if err != nil {
+		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while creating protect filter: %w", err)
+	}
+	uploadPhases, err := NewUploadPhases(config.Sync.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("FtpDeployer::NewFtpDeployer error while creating upload phases: %w", err)
+	}
 
 	return &FtpDeployer{
 		config:           config,
+		configPath:       configPath,
+		options:          options,
 		ftpConnection:    ftpConnection,
+		throttle:         uploadThrottle,
 		ftpFactory:       ftpFactory,
 		systemFactory:    systemFactory,
 		logFactory:       logFactory,
 		fileSystemFilter: fileSystemFilter,
+		protectFilter:    protectFilter,
+		uploadPhases:     uploadPhases,
 		envGenerator:     envGenerator,
+		stepOutputs:      stepOutputs,
+		masker:           masker,
+		syncVariables:    syncVariables,
+		release:          deployVariables["release"],
 	}, nil
 }
 
+// FtpDeployer::SetUploadRates change upload bandwidth limits of running deploy
+func (f *FtpDeployer) SetUploadRates(sync FtpSyncConfig) error {
+	globalRate, connectionRate, err := uploadRates(sync)
+	if err != nil {
+		return fmt.Errorf("FtpDeployer::SetUploadRates error while reading upload rates: %w", err)
+	}
+	f.throttle.SetRates(globalRate, connectionRate)
+	logrus.Infof("Upload rate changed to %s (per connection %s)", formatRate(globalRate), formatRate(connectionRate))
+	return nil
+}
+
 func (f *FtpDeployer) Close() {
 	f.ftpConnection.Close()
 }
@@ -107,7 +190,11 @@ func (f *FtpDeployer) doGenerate(ctx context.Context, generatorConfig GeneratorC
 		if err != nil {
 			return fmt.Errorf("FtpDeployer::doGenerate [EnvironmentGenerator] error while reading template file %s: %w", templatePath, err)
 		}
-		res, err := f.envGenerator.Generate(ctx, tmpl)
+		escapedGenerator, err := f.envGenerator.WithEscape(generatorConfig.Arguments["escape"])
+		if err != nil {
+			return fmt.Errorf("FtpDeployer::doGenerate [EnvironmentGenerator] error while creating generator: %w", err)
+		}
+		res, err := escapedGenerator.Generate(ctx, tmpl)
 		if err != nil {
 			return fmt.Errorf("FtpDeployer::doGenerate [EnvironmentGenerator] error while generating environment file: %w", err)
 		}
@@ -120,9 +207,8 @@ func (f *FtpDeployer) doGenerate(ctx context.Context, generatorConfig GeneratorC
 		}
 		return nil
 	default:
-		logrus.Warningf("Unknown generator type: %s", generatorConfig.Type)
+		return fmt.Errorf("FtpDeployer::doGenerate unknown generator type: %s", generatorConfig.Type)
 	}
-	return nil
 }
 
 // FtpDeployer::doMove move file from source to destination on ftp
@@ -142,57 +228,43 @@ func (f *FtpDeployer) doMove(ctx context.Context, moverConfig MoveConfig) error
 }
 
 // FtpDeployer::doAction [HttpAction] call http request
+// FtpDeployer::doAction [CommandAction] run local command
+// outputs declared by action are stored and available in later templates
 func (f *FtpDeployer) doAction(ctx context.Context, actionConfig ActionConfig) error {
+	extractors, err := newOutputExtractors(actionConfig)
+	if err != nil {
+		return fmt.Errorf("FtpDeployer::doAction error while creating outputs: %w", err)
+	}
 	switch actionConfig.Type {
 	case HttpAction:
-		urlArg, ok := actionConfig.Arguments["url"]
-		if !ok {
-			return fmt.Errorf("FtpDeployer::doAction [HttpAction] missing url argument for action: %s", actionConfig.Type)
+		httpAction, err := newHttpAction(ctx, f.envGenerator, f.masker, actionConfig)
+		if err != nil {
+			return fmt.Errorf("FtpDeployer::doAction [HttpAction] error while creating http action: %w", err)
 		}
-		url, err := f.envGenerator.Generate(ctx, []byte(fmt.Sprintf("%s", urlArg)))
+		response, err := httpAction.Call(ctx)
 		if err != nil {
-			return fmt.Errorf("FtpDeployer::doAction [HttpAction] error while generating url: %w", err)
+			return fmt.Errorf("FtpDeployer::doAction [HttpAction] error while calling http request: %w", err)
 		}
-
-		methodArg, ok := actionConfig.Arguments["method"]
-		if !ok {
-			return fmt.Errorf("FtpDeployer::doAction [HttpAction] missing method argument for action: %s", actionConfig.Type)
+		if err := f.stepOutputs.Store(actionConfig.Id, extractors, response.Body, response.Header); err != nil {
+			return fmt.Errorf("FtpDeployer::doAction [HttpAction] error while storing outputs: %w", err)
 		}
-		method, err := f.envGenerator.Generate(ctx, []byte(fmt.Sprintf("%s", methodArg)))
+		return nil
+	case CommandAction:
+		commandAction, err := newCommandAction(ctx, f.envGenerator, actionConfig, f.config.Sync.Source)
 		if err != nil {
-			return fmt.Errorf("FtpDeployer::doAction [HttpAction] error while generating method: %w", err)
+			return fmt.Errorf("FtpDeployer::doAction [CommandAction] error while creating command action: %w", err)
 		}
-
-		headers := make(map[string]string)
-		headersArg, ok := actionConfig.Arguments["headers"]
-		if ok {
-			for k, v := range headersArg.(map[string]interface{}) {
-				res, err := f.envGenerator.Generate(ctx, []byte(fmt.Sprintf("%s", v)))
-				if err != nil {
-					return fmt.Errorf("FtpDeployer::doAction [HttpAction] error while generating header %s: %w", k, err)
-				}
-				headers[k] = string(res)
-			}
-		}
-
-		body := new(bytes.Buffer)
-		bodyArg, ok := actionConfig.Arguments["body"]
-		if ok {
-			res, err := f.envGenerator.Generate(ctx, []byte(fmt.Sprintf("%s", bodyArg)))
-			if err != nil {
-				return fmt.Errorf("FtpDeployer::doAction [HttpAction] error while generating body: %w", err)
-			}
-			body.Write(res)
+		stdout, err := commandAction.Call(ctx)
+		if err != nil {
+			return fmt.Errorf("FtpDeployer::doAction [CommandAction] error while running command: %w", err)
 		}
-
-		if err := action.NewHttpAction(string(url), string(method), headers, body).Do(ctx); err != nil {
-			return fmt.Errorf("FtpDeployer::doAction [HttpAction] error while calling http request: %w", err)
+		if err := f.stepOutputs.Store(actionConfig.Id, extractors, stdout, nil); err != nil {
+			return fmt.Errorf("FtpDeployer::doAction [CommandAction] error while storing outputs: %w", err)
 		}
 		return nil
 	default:
-		logrus.Warningf("Unknown action type: %s", actionConfig.Type)
+		return fmt.Errorf("FtpDeployer::doAction unknown action type: %s", actionConfig.Type)
 	}
-	return nil
 }
 
 // FtpDeployer::doClean delete files and folders on FTP
@@ -254,19 +326,47 @@ func (f *FtpDeployer) doStep(ctx context.Context, stepConfig StepConfig) error {
 	return nil
 }
 
-func (f *FtpDeployer) sync(ctx context.Context) error {
-	// read system objects
+// FtpDeployer::readSystemObjects read local objects hashed by algorithm, only files changed since last deploy are hashed
+func (f *FtpDeployer) readSystemObjects(ctx context.Context, algorithm string) ([]CompareObject, error) {
+	hashReader := f.systemFactory.HashReader(algorithm)
+	var hashCache *file_system.HashCache
+	cachePath := ""
+	if algorithm != helpers.HashSizeMtime {
+		var err error
+		cachePath, err = hashCachePath(f.config.Sync)
+		if err != nil {
+			return nil, fmt.Errorf("FtpDeployer::readSystemObjects error while getting hash cache path: %w", err)
+		}
+		hashCache, err = file_system.LoadHashCache(cachePath)
+		if err != nil {
+			return nil, fmt.Errorf("FtpDeployer::readSystemObjects error while loading hash cache: %w", err)
+		}
+		hashReader = file_system.NewCachedHashReader(hashCache, hashReader, algorithm, f.options.Rehash)
+	}
 	systemObjects, err := NewReaderManager(
 		f.systemFactory.RecursiveLister(),
-		f.systemFactory.HashReader(),
+		hashReader,
 		f.fileSystemFilter,
+		f.config.Sync.Concurrency.HashWorkers(),
 	).Read(ctx, f.config.Sync.Source)
-
 	if err != nil {
-		return fmt.Errorf("FtpDeployer::sync error while reading system objects: %w", err)
+		return nil, fmt.Errorf("FtpDeployer::readSystemObjects error while reading system objects: %w", err)
+	}
+	if hashCache != nil {
+		if err := hashCache.Save(cachePath); err != nil {
+			logrus.Warnf("Hash cache was not saved: %s", err)
+		}
 	}
+	return systemObjects, nil
+}
 
-	// read ftp objects
+func (f *FtpDeployer) sync(ctx context.Context) error {
+	algorithm := f.config.Sync.HashAlgorithm
+	if algorithm == "" {
+		algorithm = helpers.HashSha256
+	}
+
+	// read log file, its hashes are compared with local files hashed by the same algorithm
 	logLister := f.logFactory.Lister(
 		f.ftpFactory.RecursiveLister(),
 		f.ftpFactory.CompressionReader(),
@@ -275,62 +375,187 @@ func (f *FtpDeployer) sync(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("FtpDeployer::sync error while reading log file: %w", err)
 	}
+	compareAlgorithm := compareHashAlgorithm(algorithm, logFile)
+	if compareAlgorithm != algorithm {
+		logrus.Infof("Migrating log file hashes from %s to %s...", compareAlgorithm, algorithm)
+	}
+
+	// read system objects
+	systemObjects, err := f.readSystemObjects(ctx, compareAlgorithm)
+	if err != nil {
+		return fmt.Errorf("FtpDeployer::sync error while reading system objects: %w", err)
+	}
+
+	// read ftp objects
 	var finalFtpHashReader file_system.HashReader
 	if logFile != nil {
-		finalFtpHashReader = f.logFactory.HashReader(*logFile, f.ftpFactory.HashReader())
+		finalFtpHashReader = f.logFactory.HashReader(*logFile, f.ftpFactory.HashReader(compareAlgorithm))
 	} else {
-		finalFtpHashReader = f.ftpFactory.HashReader()
+		finalFtpHashReader = f.ftpFactory.HashReader(compareAlgorithm)
 	}
 
 	ftpObjects, err := NewReaderManager(
 		logLister,
 		finalFtpHashReader,
 		f.fileSystemFilter,
+		f.config.Sync.Concurrency.HashWorkers(),
 	).Read(ctx, f.config.Sync.Destination)
-
+	// remote files are hashed only while reading, hash connections would hold connection slots of server
+	f.ftpConnection.CloseHashConnections()
 	if err != nil {
 		return fmt.Errorf("FtpDeployer::sync error while reading ftp objects: %w", err)
 	}
 
+	// remember existing remote directories, so they are not created again, directories from log file
+	// are not trusted because they may have been deleted on server since last deploy
+	if logFile == nil {
+		for _, o := range ftpObjects {
+			if o.IsDir() {
+				f.ftpFactory.DirCache().Add(fmt.Sprintf("%s/%s", f.config.Sync.Destination, o.Path()))
+			}
+		}
+		if len(ftpObjects) > 0 {
+			f.ftpFactory.DirCache().Add(f.config.Sync.Destination)
+		}
+	}
+
+	// protected remote objects are not managed by sync
+	protected := newProtectFilter(f.config.Sync.Protect, f.protectFilter, ftpObjects)
+	managedFtpObjects := managedObjects(ftpObjects, protected)
+
+	// hash directories from their children, unchanged subtrees are skipped by compare
+	systemObjects = setDirectoryHashes(systemObjects)
+	managedFtpObjects = setDirectoryHashes(managedFtpObjects)
+
 	// convert object to map
 	mapSystemObjects := helpers.ConvertToMap(systemObjects)
-	mapFtpObjects := helpers.ConvertToMap(ftpObjects)
+	mapFtpObjects := helpers.ConvertToMap(managedFtpObjects)
 
-	// compare objects
-	diff := NewCompareManager().Compare(mapSystemObjects, mapFtpObjects)
+	// compare objects, only paths from previous manifest are owned by deployer
+	owned := make(map[string]bool)
+	if logFile != nil {
+		for _, o := range logFile.Objects {
+			owned[o.Path] = true
+		}
+	}
+	diff := NewCompareManager(f.config.Sync.DeletePolicy, owned, helpers.IsUniqueHash(compareAlgorithm)).Compare(mapSystemObjects, mapFtpObjects)
+	if !f.options.AllowMassDelete {
+		if err := checkDeletions(diff, f.config.Sync.MaxDeletions, len(managedFtpObjects)); err != nil {
+			return fmt.Errorf("FtpDeployer::sync error while checking deletions: %w", err)
+		}
+	}
+	f.syncDiff = diff
+	for _, summary := range summarizeDiff(diff) {
+		logrus.Infof("Sync plan %s", summary)
+	}
+	f.previousLogFile = logFile
+	setSyncVariables(f.syncVariables, diff)
+
+	// backup remote files for rollback
+	if err := f.backup(ctx, diff); err != nil {
+		return fmt.Errorf("FtpDeployer::sync error while backing up remote files: %w", err)
+	}
 
-	// resolve diff files
-	if err := NewResolverManager(
+	// resolve diff files, in continue on error mode failed operations are reported and log file is written,
+	// so the next sync retries only them
+	resolver := NewResolverManager(
 		f.systemFactory.Reader(),
 		f.ftpFactory.Writer(),
 		f.ftpFactory.Creator(),
 		f.ftpFactory.Deleter(),
+		f.ftpFactory.Renamer(),
+		protected,
+		f.uploadPhases,
+		f.config.Sync.Concurrency.TransferWorkers(),
+		f.config.Sync.ContinueOnError,
 		f.config.Sync.Source,
 		f.config.Sync.Destination,
-	).Resolve(ctx, diff); err != nil {
-		return fmt.Errorf("FtpDeployer::sync error while resolving: %w", err)
+	)
+	resolveErr := resolver.Resolve(ctx, diff)
+	report := resolver.Report()
+	if resolveErr != nil && len(report.Failures) == 0 {
+		return fmt.Errorf("FtpDeployer::sync error while resolving: %w", resolveErr)
+	}
+	for _, failure := range report.Failures {
+		if errors.Is(failure.Err, ErrResolveSkipped) {
+			logrus.Warnf("Sync skipped %s %s: %s", failure.Result.Action, failure.Result.Object.Path(), failure.Err)
+			continue
+		}
+		logrus.Errorf("Sync failed to %s %s: %s", failure.Result.Action, failure.Result.Object.Path(), failure.Err)
+	}
+
+	// hash local objects by configured algorithm when log file used another one
+	if compareAlgorithm != algorithm {
+		systemObjects, err = f.readSystemObjects(ctx, algorithm)
+		if err != nil {
+			return fmt.Errorf("FtpDeployer::sync error while migrating hashes: %w", err)
+		}
+		systemObjects = setDirectoryHashes(systemObjects)
 	}
 
 	// upload log file
-	logObjects := make([]file_system.LogObject, len(systemObjects))
-	for i, o := range systemObjects {
-		logObjects[i] = file_system.LogObject{
+	logObjects := newLogObjects(systemObjects, logFile, report)
+	if err := f.writeLogFile(ctx, file_system.LogFile{Algorithm: algorithm, Objects: logObjects}); err != nil {
+		return fmt.Errorf("FtpDeployer::sync error while writing log file: %w", err)
+	}
+
+	if resolveErr != nil {
+		return fmt.Errorf("FtpDeployer::sync error while resolving: %w", resolveErr)
+	}
+	return nil
+}
+
+// compareHashAlgorithm return algorithm of hashes compared with log file, when log file was written with other algorithm
+// than configured one, local files are hashed by algorithm of log file and log file is migrated after sync
+func compareHashAlgorithm(algorithm string, logFile *file_system.LogFile) string {
+	if logFile != nil {
+		return logFile.HashAlgorithm()
+	}
+	return algorithm
+}
+
+// newLogObjects create log file objects from local objects, paths of failed operations keep their entry
+// from previous log file (or are left out), so they are resolved again by the next sync
+func newLogObjects(systemObjects []CompareObject, previous *file_system.LogFile, report ResolveReport) []file_system.LogObject {
+	failed := make(map[string]bool)
+	for _, failure := range report.Failures {
+		failed[failure.Result.Object.Path()] = true
+		if failure.Result.From != nil {
+			failed[failure.Result.From.Path()] = true
+		}
+	}
+	logObjects := make([]file_system.LogObject, 0, len(systemObjects))
+	for _, o := range systemObjects {
+		if failed[o.Path()] {
+			continue
+		}
+		logObjects = append(logObjects, file_system.LogObject{
 			Path:          o.Path(),
 			IsDirFlag:     o.IsDir(),
 			IsRegularFlag: !o.IsDir(),
 			Hash:          o.Hash(),
+		})
+	}
+	if previous != nil && len(failed) > 0 {
+		for _, o := range previous.Objects {
+			if failed[o.Path] {
+				logObjects = append(logObjects, o)
+			}
 		}
 	}
+	return logObjects
+}
 
+func (f *FtpDeployer) writeLogFile(ctx context.Context, logFile file_system.LogFile) error {
 	// marshal objects
-	b, err := json.Marshal(file_system.LogFile{Objects: logObjects})
+	b, err := json.Marshal(logFile)
 	if err != nil {
-		return fmt.Errorf("FtpDeployer::sync error while marshalling log file: %w", err)
+		return fmt.Errorf("FtpDeployer::writeLogFile error while marshalling log file: %w", err)
 	}
 
 	// create log file directory
 	if err := f.ftpFactory.Creator().CreateDir(ctx, helpers.GetDirectoryPath(f.config.Sync.LogFileDest)); err != nil {
-		return fmt.Errorf("FtpDeployer::sync error while creating log file directory: %w", err)
+		return fmt.Errorf("FtpDeployer::writeLogFile error while creating log file directory: %w", err)
 	}
 
 	if err := f.ftpFactory.
@@ -340,12 +565,117 @@ func (f *FtpDeployer) sync(ctx context.Context) error {
 			f.config.Sync.LogFileDest,
 			b,
 		); err != nil {
-		return fmt.Errorf("FtpDeployer::sync error while writing log file: %w", err)
+		return fmt.Errorf("FtpDeployer::writeLogFile error while writing log file: %w", err)
 	}
 
 	return nil
 }
 
+// newBackupDir create empty directory for backup of one deploy, in configured backup directory it is named by release,
+// so files backed up by previous deploys are never restored, without configured directory temporary one is created
+func newBackupDir(backupDir string, release string) (string, error) {
+	if backupDir == "" {
+		tmp, err := os.MkdirTemp("", "deployer-backup-")
+		if err != nil {
+			return "", fmt.Errorf("newBackupDir error while creating temporary directory: %w", err)
+		}
+		return tmp, nil
+	}
+	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+		return "", fmt.Errorf("newBackupDir error while creating directory %s: %w", backupDir, err)
+	}
+	path := filepath.Join(backupDir, release)
+	if err := os.Mkdir(path, 0o755); err != nil {
+		return "", fmt.Errorf("newBackupDir error while creating directory %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// FtpDeployer::backup store remote versions of changed and deleted files when rollback is enabled
+func (f *FtpDeployer) backup(ctx context.Context, diff []CompareResult) error {
+	if f.config.HealthCheck == nil || !f.config.HealthCheck.Rollback {
+		return nil
+	}
+	backupPath, err := newBackupDir(f.config.HealthCheck.BackupDir, f.release)
+	if err != nil {
+		return fmt.Errorf("FtpDeployer::backup error while creating backup directory: %w", err)
+	}
+	if f.config.HealthCheck.BackupDir == "" {
+		f.backupPath = backupPath
+	}
+	logrus.Infof("Backing up remote files to %s...", backupPath)
+	f.backupManager = NewBackupManager(
+		f.ftpFactory.Reader(),
+		f.ftpFactory.Writer(),
+		f.ftpFactory.Creator(),
+		f.ftpFactory.Deleter(),
+		f.ftpFactory.Renamer(),
+		f.systemFactory.Reader(),
+		f.systemFactory.Writer(),
+		f.systemFactory.Creator(),
+		f.config.Sync.Destination,
+		backupPath,
+		f.config.Sync.Concurrency.TransferWorkers(),
+	)
+	return f.backupManager.Backup(ctx, diff)
+}
+
+// FtpDeployer::rollback revert sync and restore previous log file
+func (f *FtpDeployer) rollback(ctx context.Context) error {
+	if f.backupManager == nil {
+		return fmt.Errorf("FtpDeployer::rollback backup of remote files is missing")
+	}
+	if err := f.backupManager.Rollback(ctx, f.syncDiff); err != nil {
+		return fmt.Errorf("FtpDeployer::rollback error while reverting sync: %w", err)
+	}
+	if f.previousLogFile == nil {
+		if err := f.ftpFactory.Deleter().Delete(ctx, f.config.Sync.LogFileDest); err != nil {
+			return fmt.Errorf("FtpDeployer::rollback error while deleting log file: %w", err)
+		}
+		return nil
+	}
+	if err := f.writeLogFile(ctx, *f.previousLogFile); err != nil {
+		return fmt.Errorf("FtpDeployer::rollback error while restoring log file: %w", err)
+	}
+	return nil
+}
+
+// FtpDeployer::cleanBackup remove temporary backup directory, it is kept only when rollback failed
+func (f *FtpDeployer) cleanBackup(ctx context.Context) {
+	if f.backupPath == "" {
+		return
+	}
+	if f.keepBackup {
+		logrus.Warningf("Backup of remote files is kept in %s", f.backupPath)
+		return
+	}
+	if err := f.systemFactory.Deleter().DeleteDir(ctx, f.backupPath); err != nil {
+		logrus.Warningf("Unable to remove backup directory %s: %s", f.backupPath, err)
+	}
+}
+
+// FtpDeployer::healthCheck call health check action and rollback sync when it fails
+func (f *FtpDeployer) healthCheck(ctx context.Context) error {
+	if f.config.HealthCheck == nil {
+		return nil
+	}
+	logrus.Infof("Running health check...")
+	err := f.doAction(ctx, f.config.HealthCheck.Action)
+	if err == nil {
+		return nil
+	}
+	if !f.config.HealthCheck.Rollback {
+		return fmt.Errorf("FtpDeployer::healthCheck health check failed: %w", err)
+	}
+	logrus.Errorf("Health check failed, rolling back: %s", err)
+	if rollbackErr := f.rollback(ctx); rollbackErr != nil {
+		// backup is kept for manual restore
+		f.keepBackup = true
+		return fmt.Errorf("FtpDeployer::healthCheck health check failed (%s) and rollback failed: %w", err, rollbackErr)
+	}
+	return fmt.Errorf("FtpDeployer::healthCheck health check failed, sync was rolled back: %w", err)
+}
+
 func (f *FtpDeployer) folders(ctx context.Context, folders []string) error {
 	for _, fol := range folders {
 		if err := f.ftpFactory.Creator().CreateDir(ctx, fol); err != nil {
@@ -362,6 +692,10 @@ func (f *FtpDeployer) readableFolders(ctx context.Context, folders []string) err
 			return fmt.Errorf("FtpDeployer::readableFolders error while listing folder %s: %w", fol, err)
 		}
 		for _, r := range res {
+			// protected paths are never changed
+			if f.protectFilter.Contain(fmt.Sprintf("%s%s", fol, r.GetName()), r.IsDir()) {
+				continue
+			}
 			abs := fmt.Sprintf("%s%s", f.config.Sync.Destination, r.GetName())
 			if r.IsDir() {
 				if err := f.ftpFactory.ChangeModer().Change(ctx, abs, "0777"); err != nil {
@@ -393,6 +727,14 @@ func (f *FtpDeployer) Deploy(ctx context.Context) error {
 
 	//-------- start of final solution
 
+	// temporary backup directory is removed on every exit path
+	defer f.cleanBackup(ctx)
+
+	// check all template variables are defined before anything is changed
+	if err := NewEnvChecker(f.systemFactory.Reader(), f.envGenerator, f.configPath).Missing(ctx, f.config); err != nil {
+		return fmt.Errorf("FtpDeployer::Deploy error while checking template variables: %w", err)
+	}
+
 	// do step before
 	if err := f.doStep(ctx, f.config.Before); err != nil {
 		return fmt.Errorf("FtpDeployer::Deploy error while executing before step: %w", err)
@@ -418,5 +760,10 @@ func (f *FtpDeployer) Deploy(ctx context.Context) error {
 		return fmt.Errorf("FtpDeployer::Deploy error while executing after step: %w", err)
 	}
 
+	// do health check
+	if err := f.healthCheck(ctx); err != nil {
+		return fmt.Errorf("FtpDeployer::Deploy error while checking health: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/filter"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+func Test_newLogObjects(t *testing.T) {
+	previous := &file_system.LogFile{Objects: []file_system.LogObject{
+		{Path: "/a.txt", IsRegularFlag: true, Hash: "old-a"},
+		{Path: "/old.txt", IsRegularFlag: true, Hash: "old"},
+	}}
+	system := []CompareObject{NewFile("/a.txt", "new-a"), NewFile("/b.txt", "new-b"), NewFile("/c.txt", "new-c")}
+	report := ResolveReport{Failures: []ResolveFailure{
+		{Result: CompareResult{Object: NewFile("/a.txt", "new-a"), Action: ActionChange}, Err: errors.New("x")},
+		{Result: CompareResult{Object: NewFile("/b.txt", "new-b"), Action: ActionUpload}, Err: errors.New("x")},
+		{Result: CompareResult{Object: NewFile("/old.txt", "old"), Action: ActionDelete}, Err: errors.New("x")},
+	}}
+	got := make(map[string]string)
+	for _, o := range newLogObjects(system, previous, report) {
+		got[o.Path] = o.Hash
+	}
+	want := map[string]string{"/c.txt": "new-c", "/a.txt": "old-a", "/old.txt": "old"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newLogObjects() = %v, want %v", got, want)
+	}
+}
+
+func Test_compareHashAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{"a.php": "a", "b.php": "b"} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	systemFactory := file_system.NewSystemFactory("", "")
+	read := func(algorithm string) []CompareObject {
+		objects, err := NewReaderManager(systemFactory.RecursiveLister(), systemFactory.HashReader(algorithm), filter.NewChainFilter(), 1).
+			Read(context.Background(), dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return objects
+	}
+	tests := []struct {
+		name      string
+		algorithm string
+		logFile   *file_system.LogFile
+		want      string
+	}{
+		{
+			name:      "Test 1",
+			algorithm: helpers.HashSha256,
+			want:      helpers.HashSha256,
+		},
+		{
+			name:      "Test 2",
+			algorithm: helpers.HashSha256,
+			logFile:   &file_system.LogFile{Algorithm: helpers.HashCrc32},
+			want:      helpers.HashCrc32,
+		},
+		{
+			name:      "Test 3",
+			algorithm: helpers.HashCrc32,
+			logFile:   &file_system.LogFile{Algorithm: helpers.HashSizeMtime},
+			want:      helpers.HashSizeMtime,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareHashAlgorithm(tt.algorithm, tt.logFile)
+			if got != tt.want {
+				t.Fatalf("compareHashAlgorithm() = %v, want %v", got, tt.want)
+			}
+			if tt.logFile == nil {
+				return
+			}
+			// log file written by previous deploy with its algorithm, nothing changed since
+			previous := *tt.logFile
+			previous.Objects = newLogObjects(read(got), nil, ResolveReport{})
+			if len(previous.Objects) != 2 {
+				t.Fatalf("newLogObjects() = %v, want 2 objects", previous.Objects)
+			}
+			remote := make([]CompareObject, 0, len(previous.Objects))
+			for _, o := range previous.Objects {
+				remote = append(remote, NewFile(o.Path, o.Hash))
+			}
+			diff := NewCompareManager(DeletePolicyMirror, nil, helpers.IsUniqueHash(got)).
+				Compare(helpers.ConvertToMap(read(got)), helpers.ConvertToMap(remote))
+			if len(diff) != 0 {
+				t.Errorf("Compare() = %v, want no changes", diff)
+			}
+			// migrated log file contains hashes by configured algorithm
+			for _, o := range newLogObjects(read(tt.algorithm), &previous, ResolveReport{}) {
+				content, err := os.ReadFile(filepath.Join(dir, o.Path))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, _ := helpers.HashBytesWith(tt.algorithm, content)
+				if o.Hash != want {
+					t.Errorf("newLogObjects() hash of %s = %v, want %v", o.Path, o.Hash, want)
+				}
+			}
+		})
+	}
+}
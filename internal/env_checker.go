@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/generator"
+	"github.com/bednarradek/php-deployer/pkg/secret"
+)
+
+// TemplateReferences variables referenced by template and those of them which are not defined
+type TemplateReferences struct {
+	Location  string
+	Variables []string
+	Missing   []string
+}
+
+// EnvChecker find variables referenced by config templates which are not defined,
+// reserved namespaces are checked against config (declared secrets, action outputs) without resolving them
+type EnvChecker struct {
+	reader     file_system.Reader
+	generator  *generator.EnvironmentGenerator
+	configPath string
+}
+
+func NewEnvChecker(reader file_system.Reader, generator *generator.EnvironmentGenerator, configPath string) *EnvChecker {
+	return &EnvChecker{reader: reader, generator: generator, configPath: configPath}
+}
+
+// NewConfigEnvChecker create checker with variables loaded from env files, config and process environment,
+// secrets are not resolved so no key is needed
+func NewConfigEnvChecker(ctx context.Context, reader file_system.Reader, config *FtpConfig, configPath string) (*EnvChecker, error) {
+	g := generator.NewEnvironmentGenerator()
+	if err := loadVariables(ctx, g, reader, config, configPath); err != nil {
+		return nil, fmt.Errorf("EnvChecker::NewConfigEnvChecker error while loading variables: %w", err)
+	}
+	// functions registered during deploy, only their names matter for parsing
+	g.AddFunction("secret", func(string) (string, error) { return "", nil })
+	return NewEnvChecker(reader, g, configPath), nil
+}
+
+// EnvChecker::Check return variables referenced by every template in config and generator template files
+func (c *EnvChecker) Check(ctx context.Context, config *FtpConfig) ([]TemplateReferences, error) {
+	templates, errs := collectTemplates(ctx, c.reader, config)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("EnvChecker::Check error while collecting templates: %w", errors.Join(errs...))
+	}
+	namespaces, err := c.namespaces(config)
+	if err != nil {
+		return nil, fmt.Errorf("EnvChecker::Check error while reading namespaces: %w", err)
+	}
+
+	res := make([]TemplateReferences, 0, len(templates))
+	for _, t := range templates {
+		variables, err := c.generator.References([]byte(t.Content))
+		if err != nil {
+			return nil, fmt.Errorf("EnvChecker::Check error while parsing template %s: %w", t.Location, err)
+		}
+		references := TemplateReferences{Location: t.Location, Variables: variables, Missing: make([]string, 0)}
+		for _, variable := range variables {
+			if !c.defined(variable, namespaces) {
+				references.Missing = append(references.Missing, variable)
+			}
+		}
+		res = append(res, references)
+	}
+	return res, nil
+}
+
+// EnvChecker::Missing return error listing all undefined variables
+func (c *EnvChecker) Missing(ctx context.Context, config *FtpConfig) error {
+	references, err := c.Check(ctx, config)
+	if err != nil {
+		return err
+	}
+	missing := make([]string, 0)
+	for _, r := range references {
+		for _, m := range r.Missing {
+			missing = append(missing, fmt.Sprintf("%s (%s)", m, r.Location))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("EnvChecker::Missing undefined variables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// namespaces return names defined in reserved namespaces, steps are indexed as "<id>.<output>"
+func (c *EnvChecker) namespaces(config *FtpConfig) (map[string]map[string]bool, error) {
+	res := map[string]map[string]bool{
+		"deploy":  make(map[string]bool),
+		"sync":    make(map[string]bool),
+		"steps":   make(map[string]bool),
+		"secrets": make(map[string]bool),
+	}
+	for _, name := range deployVariableNames {
+		res["deploy"][name] = true
+	}
+	syncVariables := make(map[string]string)
+	setSyncVariables(syncVariables, nil)
+	for name := range syncVariables {
+		res["sync"][name] = true
+	}
+	actions := append(append([]ActionConfig{}, config.Before.Action...), config.After.Action...)
+	if config.HealthCheck != nil {
+		actions = append(actions, config.HealthCheck.Action)
+	}
+	for _, a := range actions {
+		for name := range a.Outputs {
+			res["steps"][fmt.Sprintf("%s.%s", a.Id, name)] = true
+		}
+	}
+	for name := range config.Secrets {
+		res["secrets"][name] = true
+	}
+	if config.SecretsFile != "" {
+		store, err := secret.ReadStore(configRelativePath(c.configPath, config.SecretsFile))
+		if err != nil {
+			return nil, fmt.Errorf("EnvChecker::namespaces error while reading secrets file: %w", err)
+		}
+		for _, name := range store.Names() {
+			res["secrets"][name] = true
+		}
+	}
+	return res, nil
+}
+
+// defined check variable against reserved namespaces or generator variables
+func (c *EnvChecker) defined(variable string, namespaces map[string]map[string]bool) bool {
+	namespace, rest, _ := strings.Cut(variable, ".")
+	if names, ok := namespaces[namespace]; ok {
+		return rest == "" || names[rest]
+	}
+	return c.generator.HasVariable(variable)
+}
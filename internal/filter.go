@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/filter"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+const (
+	DeployIgnoreFile = ".deployignore"
+	GitIgnoreFile    = ".gitignore"
+)
+
+// newSyncFilter create filter from regular expressions in ignore_list and gitignore patterns from ignore files in source
+func newSyncFilter(ctx context.Context, reader file_system.Reader, sync FtpSyncConfig) (filter.Filter, error) {
+	regexFilter, err := filter.NewFileSystemFilter(sync.IgnoreList)
+	if err != nil {
+		return nil, fmt.Errorf("newSyncFilter error while creating regex filter: %w", err)
+	}
+	patterns, err := readIgnorePatterns(ctx, reader, sync)
+	if err != nil {
+		return nil, fmt.Errorf("newSyncFilter error while reading ignore files: %w", err)
+	}
+	gitIgnoreFilter, err := filter.NewGitIgnoreFilter(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("newSyncFilter error while creating gitignore filter: %w", err)
+	}
+	return filter.NewChainFilter(regexFilter, gitIgnoreFilter), nil
+}
+
+// readIgnorePatterns read patterns from .gitignore (when enabled) and .deployignore in source, missing files are skipped
+func readIgnorePatterns(ctx context.Context, reader file_system.Reader, sync FtpSyncConfig) ([]string, error) {
+	files := []string{DeployIgnoreFile}
+	if sync.UseGitignore {
+		// .deployignore is read last, so it can re-include files ignored by .gitignore
+		files = []string{GitIgnoreFile, DeployIgnoreFile}
+	}
+	res := make([]string, 0)
+	for _, file := range files {
+		content, err := reader.Read(ctx, fmt.Sprintf("%s/%s", sync.Source, file))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("readIgnorePatterns error while reading %s: %w", file, err)
+		}
+		res = append(res, filter.ParseIgnoreFile(content)...)
+	}
+	return res, nil
+}
+
+// protectFilter contain remote paths matched by protect patterns and directories containing them,
+// so deleting parent directory of protected file is refused as well
+type protectFilter struct {
+	patterns filter.Filter
+	parents  map[string]bool
+}
+
+// newProtectFilter create filter of protected paths, parents are directories of literal prefixes of patterns
+// (remote listing may be only the log file, so protected files are not known) and parents of remote objects matched by patterns
+func newProtectFilter(protect []string, patterns filter.Filter, remoteObjects []CompareObject) *protectFilter {
+	parents := make(map[string]bool)
+	for _, p := range protect {
+		for _, dir := range protectAncestors(p) {
+			parents[dir] = true
+		}
+	}
+	for _, o := range remoteObjects {
+		if !patterns.Contain(o.Path(), o.IsDir()) {
+			continue
+		}
+		for dir := helpers.GetDirectoryPath(o.Path()); dir != ""; dir = helpers.GetDirectoryPath(dir) {
+			parents[dir] = true
+		}
+	}
+	return &protectFilter{patterns: patterns, parents: parents}
+}
+
+func (p *protectFilter) Contain(path string, isDir bool) bool {
+	return p.patterns.Contain(path, isDir) || (isDir && p.parents[path])
+}
+
+// protectAncestors return directories containing every path matched by protect pattern,
+// e.g. /public and /public/uploads for /public/uploads/*.jpg, unanchored patterns have no such directory
+func protectAncestors(pattern string) []string {
+	p := strings.TrimSpace(pattern)
+	if p == "" || strings.HasPrefix(p, "#") || strings.HasPrefix(p, "!") {
+		return nil
+	}
+	p = strings.TrimSuffix(p, "/")
+	// pattern without slash (except trailing one) matches at any level
+	if !strings.Contains(p, "/") {
+		return nil
+	}
+	segments := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	literal := 0
+	for literal < len(segments) && !strings.ContainsAny(segments[literal], "*?[\\") {
+		literal++
+	}
+	if literal == len(segments) {
+		// last segment is the protected path itself
+		literal--
+	}
+	if literal == 0 {
+		return nil
+	}
+	res := make([]string, 0, literal)
+	for i := 1; i <= literal; i++ {
+		res = append(res, "/"+strings.Join(segments[:i], "/"))
+	}
+	return res
+}
+
+// managedObjects return remote objects managed by sync, protected paths and directories containing them are left out,
+// so they are never planned for deletion
+func managedObjects(objects []CompareObject, protected filter.Filter) []CompareObject {
+	res := make([]CompareObject, 0, len(objects))
+	for _, o := range objects {
+		if !protected.Contain(o.Path(), o.IsDir()) {
+			res = append(res, o)
+		}
+	}
+	return res
+}
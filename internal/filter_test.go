@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bednarradek/php-deployer/pkg/filter"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+func Test_protectAncestors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{name: "Test 1", pattern: "/public/uploads/", want: []string{"/public"}},
+		{name: "Test 2", pattern: "/public/uploads/*.jpg", want: []string{"/public", "/public/uploads"}},
+		{name: "Test 3", pattern: "storage/logs/", want: []string{"/storage"}},
+		{name: "Test 4", pattern: "*.log", want: nil},
+		{name: "Test 5", pattern: "**/cache", want: nil},
+		{name: "Test 6", pattern: "!/public/uploads/keep", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := protectAncestors(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("protectAncestors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_managedObjects(t *testing.T) {
+	protect := []string{"/public/uploads/*.jpg"}
+	patterns, err := filter.NewGitIgnoreFilter(protect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// parent directories of protected file exist only on server
+	remote := []CompareObject{
+		NewFolder("/public"),
+		NewFolder("/public/uploads"),
+		NewFile("/public/uploads/a.jpg", "a"),
+		NewFile("/old.php", "o"),
+	}
+	source := []CompareObject{NewFile("/index.php", "i")}
+	protected := newProtectFilter(protect, patterns, remote)
+
+	managed := managedObjects(remote, protected)
+	want := []CompareObject{NewFile("/old.php", "o")}
+	if !reflect.DeepEqual(managed, want) {
+		t.Fatalf("managedObjects() = %v, want %v", managed, want)
+	}
+	diff := NewCompareManager(DeletePolicyMirror, nil, true).Compare(helpers.ConvertToMap(source), helpers.ConvertToMap(managed))
+	p := &ResolverManager{protected: protected}
+	if err := p.checkProtected(diff); err != nil {
+		t.Errorf("checkProtected() error = %v, want nil", err)
+	}
+}
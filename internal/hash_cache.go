@@ -0,0 +1,25 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+// hashCachePath return path of local hash cache, by default file in user cache directory unique for source
+func hashCachePath(sync FtpSyncConfig) (string, error) {
+	if sync.HashCacheFile != "" {
+		return sync.HashCacheFile, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("hashCachePath error while getting user cache directory: %w", err)
+	}
+	source, err := filepath.Abs(sync.Source)
+	if err != nil {
+		return "", fmt.Errorf("hashCachePath error while getting absolute path of source: %w", err)
+	}
+	return filepath.Join(dir, "php-deployer", fmt.Sprintf("%s.json", helpers.HashBytes([]byte(source))[:16])), nil
+}
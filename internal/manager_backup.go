@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+// BackupManager keeps remote versions of files touched by sync and is able to revert the sync diff
+type BackupManager struct {
+	remoteReader  file_system.Reader
+	remoteWriter  file_system.Writer
+	remoteCreator file_system.Creator
+	remoteDeleter file_system.Deleter
+	remoteRenamer file_system.Renamer
+	backupReader  file_system.Reader
+	backupWriter  file_system.Writer
+	backupCreator file_system.Creator
+	remotePath    string
+	backupPath    string
+	workers       int
+}
+
+func NewBackupManager(
+	remoteReader file_system.Reader,
+	remoteWriter file_system.Writer,
+	remoteCreator file_system.Creator,
+	remoteDeleter file_system.Deleter,
+	remoteRenamer file_system.Renamer,
+	backupReader file_system.Reader,
+	backupWriter file_system.Writer,
+	backupCreator file_system.Creator,
+	remotePath string,
+	backupPath string,
+	workers int,
+) *BackupManager {
+	return &BackupManager{
+		remoteReader:  remoteReader,
+		remoteWriter:  remoteWriter,
+		remoteCreator: remoteCreator,
+		remoteDeleter: remoteDeleter,
+		remoteRenamer: remoteRenamer,
+		backupReader:  backupReader,
+		backupWriter:  backupWriter,
+		backupCreator: backupCreator,
+		remotePath:    remotePath,
+		backupPath:    backupPath,
+		workers:       workers,
+	}
+}
+
+// BackupManager::Backup download remote version of every file which will be changed or deleted by diff,
+// renamed files keep their content so they are only renamed back during rollback
+func (m *BackupManager) Backup(ctx context.Context, diff []CompareResult) error {
+	_, err := helpers.RunWorkers(ctx, m.workers, diff, func(ctx context.Context, i CompareResult) (interface{}, error) {
+		if i.Object.IsDir() || i.Action == ActionUpload || i.Action == ActionRename {
+			return nil, nil
+		}
+		content, err := m.remoteReader.Read(ctx, m.remote(i.Object))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("BackupManager::Backup error while reading remote file %s: %w", i.Object.Path(), err)
+		}
+		// file missing on server (FTP reader returns no content) has nothing to back up
+		if err != nil || content == nil {
+			return nil, nil
+		}
+		backup := m.backup(i.Object)
+		if err := m.backupCreator.CreateDir(ctx, helpers.GetDirectoryPath(backup)); err != nil {
+			return nil, fmt.Errorf("BackupManager::Backup error while creating backup directory for %s: %w", i.Object.Path(), err)
+		}
+		if err := m.backupWriter.Write(ctx, backup, content); err != nil {
+			return nil, fmt.Errorf("BackupManager::Backup error while writing backup of %s: %w", i.Object.Path(), err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("BackupManager::Backup error while backing up files: %w", err)
+	}
+	return nil
+}
+
+// BackupManager::Rollback revert diff applied by ResolverManager
+// - recreate deleted directories (parents first)
+// - delete uploaded files, rename renamed files back and restore changed and deleted files from backup
+// - delete created directories (deepest first) when they are empty
+// changed files without backup were missing on server before sync, so they are deleted
+func (m *BackupManager) Rollback(ctx context.Context, diff []CompareResult) error {
+	createdDirs := make([]CompareResult, 0, 100)
+	deletedDirs := make([]CompareResult, 0, 100)
+	files := make([]CompareResult, 0, 100)
+	for _, result := range diff {
+		if !result.Object.IsDir() {
+			files = append(files, result)
+			continue
+		}
+		switch result.Action {
+		case ActionUpload:
+			createdDirs = append(createdDirs, result)
+		case ActionDelete:
+			deletedDirs = append(deletedDirs, result)
+		}
+	}
+
+	sort.Slice(deletedDirs, func(i, j int) bool {
+		return strings.Count(deletedDirs[i].Object.Path(), "/") < strings.Count(deletedDirs[j].Object.Path(), "/")
+	})
+	for _, d := range deletedDirs {
+		if err := m.remoteCreator.CreateDir(ctx, m.remote(d.Object)); err != nil {
+			return fmt.Errorf("BackupManager::Rollback error while recreating directory %s: %w", d.Object.Path(), err)
+		}
+	}
+
+	_, err := helpers.RunWorkers(ctx, m.workers, files, func(ctx context.Context, i CompareResult) (interface{}, error) {
+		if i.Action == ActionUpload {
+			if err := m.remoteDeleter.Delete(ctx, m.remote(i.Object)); err != nil {
+				return nil, fmt.Errorf("BackupManager::Rollback error while deleting uploaded file %s: %w", i.Object.Path(), err)
+			}
+			return nil, nil
+		}
+		if i.Action == ActionRename {
+			if err := m.remoteRenamer.Rename(ctx, m.remote(i.Object), m.remote(i.From)); err != nil {
+				return nil, fmt.Errorf("BackupManager::Rollback error while renaming back file %s: %w", i.Object.Path(), err)
+			}
+			return nil, nil
+		}
+		content, err := m.backupReader.Read(ctx, m.backup(i.Object))
+		if errors.Is(err, fs.ErrNotExist) {
+			// file was missing on server before sync, uploaded version is removed
+			if i.Action == ActionChange {
+				if err := m.remoteDeleter.Delete(ctx, m.remote(i.Object)); err != nil {
+					return nil, fmt.Errorf("BackupManager::Rollback error while deleting uploaded file %s: %w", i.Object.Path(), err)
+				}
+			}
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("BackupManager::Rollback error while reading backup of %s: %w", i.Object.Path(), err)
+		}
+		if err := m.remoteWriter.Write(ctx, m.remote(i.Object), content); err != nil {
+			return nil, fmt.Errorf("BackupManager::Rollback error while restoring file %s: %w", i.Object.Path(), err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("BackupManager::Rollback error while restoring files: %w", err)
+	}
+
+	sort.Slice(createdDirs, func(i, j int) bool {
+		return strings.Count(createdDirs[i].Object.Path(), "/") > strings.Count(createdDirs[j].Object.Path(), "/")
+	})
+	for _, d := range createdDirs {
+		// directory may contain files created on server since sync, so only empty one is deleted
+		if err := m.remoteDeleter.DeleteEmptyDir(ctx, m.remote(d.Object)); err != nil {
+			return fmt.Errorf("BackupManager::Rollback error while deleting created directory %s: %w", d.Object.Path(), err)
+		}
+	}
+	return nil
+}
+
+func (m *BackupManager) remote(object CompareObject) string {
+	return fmt.Sprintf("%s/%s", m.remotePath, object.Path())
+}
+
+func (m *BackupManager) backup(object CompareObject) string {
+	return fmt.Sprintf("%s/%s", m.backupPath, object.Path())
+}
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+)
+
+// readTree return content of every file under dir indexed by slash separated relative path
+func readTree(t *testing.T, dir string) map[string]string {
+	res := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		res["/"+filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	for path, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBackupManager_Rollback(t *testing.T) {
+	remote := t.TempDir()
+	backup := t.TempDir()
+	before := map[string]string{
+		"/index.php":   "old index",
+		"/old.php":     "old",
+		"/app/a.php":   "a",
+		"/keep/k.php":  "k",
+		"/config.neon": "config",
+	}
+	writeTree(t, remote, before)
+	diff := []CompareResult{
+		{Object: NewFile("/index.php", "new"), Action: ActionChange},
+		{Object: NewFile("/old.php", "old"), Action: ActionDelete},
+		{Object: NewFolder("/src"), Action: ActionUpload},
+		{Object: NewFolder("/cache"), Action: ActionUpload},
+		{Object: NewFile("/src/a.php", "a"), Action: ActionRename, From: NewFile("/app/a.php", "a")},
+		{Object: NewFile("/src/b.php", "b"), Action: ActionUpload},
+		// removed from server after listing, nothing to back up
+		{Object: NewFile("/missing.php", "m"), Action: ActionChange},
+	}
+	factory := file_system.NewSystemFactory("0644", "0755")
+	manager := NewBackupManager(
+		factory.Reader(), factory.Writer(), factory.Creator(), factory.Deleter(), factory.Renamer(),
+		factory.Reader(), factory.Writer(), factory.Creator(),
+		remote, backup, 2,
+	)
+	if err := manager.Backup(context.Background(), diff); err != nil {
+		t.Fatalf("BackupManager::Backup() error = %v", err)
+	}
+	want := map[string]string{"/index.php": "old index", "/old.php": "old"}
+	if got := readTree(t, backup); !reflect.DeepEqual(got, want) {
+		t.Errorf("BackupManager::Backup() backup = %v, want %v", got, want)
+	}
+
+	// apply diff
+	if err := os.Remove(filepath.Join(remote, "old.php")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(remote, "src"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(remote, "app", "a.php"), filepath.Join(remote, "src", "a.php")); err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, remote, map[string]string{"/index.php": "new", "/src/b.php": "b", "/missing.php": "m"})
+	// file created on server by application after sync
+	writeTree(t, remote, map[string]string{"/cache/c.tmp": "c"})
+	before["/cache/c.tmp"] = "c"
+
+	if err := manager.Rollback(context.Background(), diff); err != nil {
+		t.Fatalf("BackupManager::Rollback() error = %v", err)
+	}
+	if got := readTree(t, remote); !reflect.DeepEqual(got, before) {
+		t.Errorf("BackupManager::Rollback() remote = %v, want %v", got, before)
+	}
+	if _, err := os.Stat(filepath.Join(remote, "src")); !os.IsNotExist(err) {
+		t.Errorf("BackupManager::Rollback() created directory /src was not deleted: %v", err)
+	}
+}
+
+func Test_newBackupDir(t *testing.T) {
+	dir := t.TempDir()
+	// backup of previous deploy
+	writeTree(t, dir, map[string]string{"/20240101000000/index.php": "old"})
+
+	path, err := newBackupDir(dir, "20240102000000")
+	if err != nil {
+		t.Fatalf("newBackupDir() error = %v", err)
+	}
+	if got := readTree(t, path); len(got) != 0 {
+		t.Errorf("newBackupDir() content = %v, want empty directory", got)
+	}
+	if _, err := newBackupDir(dir, "20240101000000"); err == nil {
+		t.Errorf("newBackupDir() error = nil, want error for existing backup")
+	}
+	tmp, err := newBackupDir("", "20240102000000")
+	if err != nil {
+		t.Fatalf("newBackupDir() error = %v", err)
+	}
+	defer os.RemoveAll(tmp)
+	if filepath.Dir(tmp) == dir {
+		t.Errorf("newBackupDir() = %v, want temporary directory", tmp)
+	}
+}
@@ -1,33 +1,174 @@
 package internal
 
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
 const (
 	ActionUpload = "upload"
 	ActionDelete = "delete"
 	ActionChange = "change"
+	ActionRename = "rename"
+)
+
+const (
+	DeletePolicyMirror = "mirror"
+	DeletePolicyOwned  = "owned"
+	DeletePolicyNone   = "none"
 )
 
 type CompareManager struct {
+	deletePolicy string
+	owned        map[string]bool
+	renames      bool
 }
 
-func NewCompareManager() *CompareManager {
-	return &CompareManager{}
+// NewCompareManager create compare manager, owned contains paths uploaded by previous deploy (used by owned policy),
+// renames are detected only when equal hashes mean equal content (see helpers.IsUniqueHash)
+func NewCompareManager(deletePolicy string, owned map[string]bool, renames bool) *CompareManager {
+	if deletePolicy == "" {
+		deletePolicy = DeletePolicyMirror
+	}
+	return &CompareManager{deletePolicy: deletePolicy, owned: owned, renames: renames}
 }
 
+// CompareManager::Compare return actions needed to sync remote with source,
+// subtrees of directories with equal hash on both sides are skipped
 func (m *CompareManager) Compare(source map[string]CompareObject, remote map[string]CompareObject) []CompareResult {
+	unchanged := make(map[string]bool)
+	for _, localObject := range source {
+		if !localObject.IsDir() || localObject.Hash() == "" {
+			continue
+		}
+		if remoteObject, ok := remote[localObject.Path()]; ok && remoteObject.Hash() == localObject.Hash() {
+			unchanged[localObject.Path()] = true
+		}
+	}
+
 	result := make([]CompareResult, 0, 100)
 	for _, localObject := range source {
+		if inUnchangedDir(localObject.Path(), unchanged) {
+			continue
+		}
 		remoteObject, ok := remote[localObject.Path()]
 		if !ok {
 			result = append(result, CompareResult{Object: localObject, Action: ActionUpload})
-		} else if localObject.Hash() != remoteObject.Hash() {
+		} else if !localObject.IsDir() && localObject.Hash() != remoteObject.Hash() {
 			result = append(result, CompareResult{Object: localObject, Action: ActionChange})
 		}
 	}
 	for _, remoteObject := range remote {
+		if inUnchangedDir(remoteObject.Path(), unchanged) {
+			continue
+		}
 		_, ok := source[remoteObject.Path()]
-		if !ok {
+		if !ok && m.deletable(remoteObject) {
 			result = append(result, CompareResult{Object: remoteObject, Action: ActionDelete})
 		}
 	}
-	return result
+	if !m.renames {
+		return result
+	}
+	return m.detectRenames(result)
+}
+
+// inUnchangedDir check whether some parent directory of path is unchanged
+func inUnchangedDir(path string, unchanged map[string]bool) bool {
+	if len(unchanged) == 0 {
+		return false
+	}
+	for dir := helpers.GetDirectoryPath(path); dir != ""; dir = helpers.GetDirectoryPath(dir) {
+		if unchanged[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareManager::detectRenames replace pairs of deleted remote file and uploaded local file with the same hash by rename
+func (m *CompareManager) detectRenames(input []CompareResult) []CompareResult {
+	// sort to pair files with the same hash deterministically
+	sort.Slice(input, func(i, j int) bool {
+		return input[i].Object.Path() < input[j].Object.Path()
+	})
+	deleted := make(map[string][]int)
+	for i, r := range input {
+		if r.Action == ActionDelete && !r.Object.IsDir() && r.Object.Hash() != "" {
+			deleted[r.Object.Hash()] = append(deleted[r.Object.Hash()], i)
+		}
+	}
+	renamedFrom := make(map[int]bool)
+	result := make([]CompareResult, 0, len(input))
+	for _, r := range input {
+		if r.Action == ActionUpload && !r.Object.IsDir() && len(deleted[r.Object.Hash()]) > 0 {
+			from := deleted[r.Object.Hash()][0]
+			deleted[r.Object.Hash()] = deleted[r.Object.Hash()][1:]
+			renamedFrom[from] = true
+			r.Action = ActionRename
+			r.From = input[from].Object
+		}
+		result = append(result, r)
+	}
+	final := make([]CompareResult, 0, len(result))
+	for i, r := range result {
+		if !renamedFrom[i] {
+			final = append(final, r)
+		}
+	}
+	return final
+}
+
+// CompareManager::deletable check delete policy for remote object missing locally,
+// deleted directories are removed only when empty, so files not uploaded by deployer are kept
+func (m *CompareManager) deletable(object CompareObject) bool {
+	switch m.deletePolicy {
+	case DeletePolicyNone:
+		return false
+	case DeletePolicyOwned:
+		return m.owned[object.Path()]
+	default:
+		return true
+	}
+}
+
+// parseMaxDeletions convert threshold given as count ("100") or percent of remote objects ("10%") to count
+func parseMaxDeletions(value string, total int) (int, error) {
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil || p < 0 || p > 100 {
+			return 0, fmt.Errorf("parseMaxDeletions invalid percent %q", value)
+		}
+		return int(float64(total) * p / 100), nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("parseMaxDeletions invalid count %q", value)
+	}
+	return count, nil
+}
+
+// checkDeletions return error when diff deletes more remote objects than max_deletions allows
+func checkDeletions(diff []CompareResult, maxDeletions string, total int) error {
+	if maxDeletions == "" {
+		return nil
+	}
+	limit, err := parseMaxDeletions(maxDeletions, total)
+	if err != nil {
+		return err
+	}
+	deletions := 0
+	for _, d := range diff {
+		if d.Action == ActionDelete {
+			deletions++
+		}
+	}
+	if deletions > limit {
+		return fmt.Errorf("checkDeletions sync deletes %d of %d remote objects, max_deletions is %s (use --allow-mass-delete to proceed)", deletions, total, maxDeletions)
+	}
+	return nil
 }
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestCompareManager_Compare(t *testing.T) {
+	source := map[string]CompareObject{
+		"/index.php": NewFile("/index.php", "a"),
+	}
+	remote := map[string]CompareObject{
+		"/index.php":  NewFile("/index.php", "b"),
+		"/old.php":    NewFile("/old.php", "c"),
+		"/upload.jpg": NewFile("/upload.jpg", "d"),
+	}
+	tests := []struct {
+		name         string
+		deletePolicy string
+		owned        map[string]bool
+		wantDeletes  int
+	}{
+		{
+			name:         "Test 1",
+			deletePolicy: "",
+			wantDeletes:  2,
+		},
+		{
+			name:         "Test 2",
+			deletePolicy: DeletePolicyOwned,
+			owned:        map[string]bool{"/index.php": true, "/old.php": true},
+			wantDeletes:  1,
+		},
+		{
+			name:         "Test 3",
+			deletePolicy: DeletePolicyOwned,
+			wantDeletes:  0,
+		},
+		{
+			name:         "Test 4",
+			deletePolicy: DeletePolicyNone,
+			wantDeletes:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deletes := 0
+			for _, r := range NewCompareManager(tt.deletePolicy, tt.owned, true).Compare(source, remote) {
+				if r.Action == ActionDelete {
+					deletes++
+				}
+			}
+			if deletes != tt.wantDeletes {
+				t.Errorf("Compare() deletes = %v, want %v", deletes, tt.wantDeletes)
+			}
+		})
+	}
+}
+
+func TestCompareManager_CompareRenames(t *testing.T) {
+	source := map[string]CompareObject{
+		"/src/a.php": NewFile("/src/a.php", "a"),
+		"/src/b.php": NewFile("/src/b.php", "b"),
+	}
+	remote := map[string]CompareObject{
+		"/app/a.php": NewFile("/app/a.php", "a"),
+		"/app/c.php": NewFile("/app/c.php", "c"),
+	}
+	want := map[string]string{
+		"/src/a.php": ActionRename,
+		"/src/b.php": ActionUpload,
+		"/app/c.php": ActionDelete,
+	}
+	got := NewCompareManager(DeletePolicyMirror, nil, true).Compare(source, remote)
+	if len(got) != len(want) {
+		t.Fatalf("Compare() = %v, want %v", got, want)
+	}
+	for _, r := range got {
+		if want[r.Object.Path()] != r.Action {
+			t.Errorf("Compare() action for %s = %v, want %v", r.Object.Path(), r.Action, want[r.Object.Path()])
+		}
+		if r.Action == ActionRename && r.From.Path() != "/app/a.php" {
+			t.Errorf("Compare() rename from = %v, want /app/a.php", r.From.Path())
+		}
+	}
+}
+
+func Test_checkDeletions(t *testing.T) {
+	diff := []CompareResult{
+		{Object: NewFile("/a.php", "a"), Action: ActionDelete},
+		{Object: NewFile("/b.php", "b"), Action: ActionDelete},
+		{Object: NewFile("/c.php", "c"), Action: ActionUpload},
+	}
+	tests := []struct {
+		name         string
+		maxDeletions string
+		total        int
+		wantErr      bool
+	}{
+		{name: "Test 1", maxDeletions: "", total: 10, wantErr: false},
+		{name: "Test 2", maxDeletions: "2", total: 10, wantErr: false},
+		{name: "Test 3", maxDeletions: "1", total: 10, wantErr: true},
+		{name: "Test 4", maxDeletions: "10%", total: 10, wantErr: true},
+		{name: "Test 5", maxDeletions: "20%", total: 10, wantErr: false},
+		{name: "Test 6", maxDeletions: "abc", total: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkDeletions(diff, tt.maxDeletions, tt.total); (err != nil) != tt.wantErr {
+				t.Errorf("checkDeletions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompareManager_CompareWithoutRenames(t *testing.T) {
+	// equal size and mtime hash of unrelated files must not be paired
+	source := map[string]CompareObject{
+		"/b.php": NewFile("/b.php", "4:1700000000"),
+	}
+	remote := map[string]CompareObject{
+		"/a.php": NewFile("/a.php", "4:1700000000"),
+	}
+	got := NewCompareManager(DeletePolicyMirror, nil, false).Compare(source, remote)
+	want := map[string]string{
+		"/b.php": ActionUpload,
+		"/a.php": ActionDelete,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Compare() = %v, want %v", got, want)
+	}
+	for _, r := range got {
+		if want[r.Object.Path()] != r.Action {
+			t.Errorf("Compare() action for %s = %v, want %v", r.Object.Path(), r.Action, want[r.Object.Path()])
+		}
+	}
+}
@@ -13,13 +13,15 @@ type ReadManager struct {
 	lister     file_system.Lister
 	hashReader file_system.HashReader
 	filter     filter.Filter
+	workers    int
 }
 
-func NewReaderManager(lister file_system.Lister, hashReader file_system.HashReader, filter filter.Filter) *ReadManager {
+func NewReaderManager(lister file_system.Lister, hashReader file_system.HashReader, filter filter.Filter, workers int) *ReadManager {
 	return &ReadManager{
 		lister:     lister,
 		hashReader: hashReader,
 		filter:     filter,
+		workers:    workers,
 	}
 }
 
@@ -29,10 +31,10 @@ func (m *ReadManager) Read(ctx context.Context, dir string) ([]CompareObject, er
 		return nil, fmt.Errorf("ReadManager::Read error while reading directory: %w", err)
 	}
 
-	result, err := helpers.RunWorkers(ctx, 10, files, func(ctx context.Context, input file_system.FileSystemObject) (CompareObject, error) {
+	result, err := helpers.RunWorkers(ctx, m.workers, files, func(ctx context.Context, input file_system.FileSystemObject) (CompareObject, error) {
 		absDir := fmt.Sprintf("%s%s", dir, input.GetName())
 		relDir := input.GetName()
-		if m.filter.Contain(relDir) {
+		if m.filter.Contain(relDir, input.IsDir()) {
 			return nil, nil
 		}
 		if input.IsDir() {
@@ -2,17 +2,51 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/filter"
 	"github.com/bednarradek/php-deployer/pkg/helpers"
+	"github.com/sirupsen/logrus"
 )
 
+// ErrResolveSkipped delete was not done because previous phase failed, new code may not be live yet
+// or deleted path may still be needed (e.g. source of failed rename)
+var ErrResolveSkipped = errors.New("skipped because previous phase failed")
+
+// ResolveFailure operation of sync diff which failed or was skipped
+type ResolveFailure struct {
+	Result CompareResult
+	Err    error
+}
+
+// ResolveReport failures collected by ResolverManager in continue on error mode
+type ResolveReport struct {
+	Failures []ResolveFailure
+}
+
+// ResolveReport::Results return failed operations, they can be resolved again
+func (r ResolveReport) Results() []CompareResult {
+	results := make([]CompareResult, len(r.Failures))
+	for i, f := range r.Failures {
+		results[i] = f.Result
+	}
+	return results
+}
+
 type ResolverManager struct {
 	localReader    file_system.Reader
 	remoteUploader file_system.Writer
 	remoteCreator  file_system.Creator
 	remoteDeleter  file_system.Deleter
+	remoteRenamer  file_system.Renamer
+	protected      filter.Filter
+	uploadPhases   []UploadPhase
+	workers        int
+	continueOnErr  bool
+	report         ResolveReport
 	localPath      string
 	remotePath     string
 }
@@ -22,6 +56,11 @@ func NewResolverManager(
 	remoteUploader file_system.Writer,
 	remoteCreator file_system.Creator,
 	remoteDeleter file_system.Deleter,
+	remoteRenamer file_system.Renamer,
+	protected filter.Filter,
+	uploadPhases []UploadPhase,
+	workers int,
+	continueOnErr bool,
 	localPath string,
 	remotePath string,
 ) *ResolverManager {
@@ -30,57 +69,122 @@ func NewResolverManager(
 		remoteUploader: remoteUploader,
 		remoteCreator:  remoteCreator,
 		remoteDeleter:  remoteDeleter,
+		remoteRenamer:  remoteRenamer,
+		protected:      protected,
+		uploadPhases:   uploadPhases,
+		workers:        workers,
+		continueOnErr:  continueOnErr,
 		localPath:      localPath,
 		remotePath:     remotePath,
 	}
 }
 
+// ResolverManager::Resolve apply diff phase by phase, first failure stops resolving,
+// in continue on error mode all create and upload phases are resolved and failures are collected into report,
+// delete phases are skipped after any failure
 func (p *ResolverManager) Resolve(ctx context.Context, input []CompareResult) error {
-	folders := make([]CompareResult, 0, 100)
-	files := make([]CompareResult, 0, 100)
-	for _, result := range input {
-		if result.Object.IsDir() {
-			folders = append(folders, result)
+	if err := p.checkProtected(input); err != nil {
+		return err
+	}
+	p.report = ResolveReport{}
+	for _, phase := range NewResolvePlan(input, p.uploadPhases) {
+		if p.continueOnErr && len(p.report.Failures) > 0 && isDeletePhase(phase) {
+			logrus.Debugf("Skipping phase %s (%d operations)...", phase.Name, len(phase.Results))
+			for _, result := range phase.Results {
+				p.report.Failures = append(p.report.Failures, ResolveFailure{Result: result, Err: ErrResolveSkipped})
+			}
+			continue
+		}
+		logrus.Debugf("Resolving phase %s (%d operations)...", phase.Name, len(phase.Results))
+		if p.continueOnErr {
+			p.resolveAll(ctx, phase.Results)
 			continue
 		}
-		files = append(files, result)
+		if err := p.resolve(ctx, phase.Results); err != nil {
+			return fmt.Errorf("ResolverManager::Resolve error while resolving phase %s: %w", phase.Name, err)
+		}
+	}
+	if len(p.report.Failures) > 0 {
+		return fmt.Errorf("ResolverManager::Resolve %d of %d operations failed", len(p.report.Failures), len(input))
 	}
-	if err := p.resolve(ctx, folders); err != nil {
-		return fmt.Errorf("ResolverManager::Resolve error while resolving folders: %w", err)
+	return nil
+}
+
+// ResolverManager::Report return failures of last Resolve in continue on error mode
+func (p *ResolverManager) Report() ResolveReport {
+	return p.report
+}
+
+// ResolverManager::checkProtected refuse whole plan when it deletes or overwrites protected path,
+// creating of protected directory is allowed because it does not change existing content
+func (p *ResolverManager) checkProtected(input []CompareResult) error {
+	violations := make([]string, 0)
+	for _, result := range input {
+		if result.Action == ActionUpload && result.Object.IsDir() {
+			continue
+		}
+		if p.protected.Contain(result.Object.Path(), result.Object.IsDir()) {
+			violations = append(violations, fmt.Sprintf("%s %s", result.Action, result.Object.Path()))
+		}
+		if result.From != nil && p.protected.Contain(result.From.Path(), result.From.IsDir()) {
+			violations = append(violations, fmt.Sprintf("%s %s", result.Action, result.From.Path()))
+		}
 	}
-	if err := p.resolve(ctx, files); err != nil {
-		return fmt.Errorf("ResolverManager::Resolve error while resolving files: %w", err)
+	if len(violations) > 0 {
+		return fmt.Errorf("ResolverManager::checkProtected plan modifies protected paths: %s", strings.Join(violations, ", "))
 	}
 	return nil
 }
 
 func (p *ResolverManager) resolve(ctx context.Context, input []CompareResult) error {
-	_, err := helpers.RunWorkers(ctx, 10, input, func(ctx context.Context, i CompareResult) (interface{}, error) {
-		switch i.Action {
-		case ActionChange:
-			if err := p.delete(ctx, i.Object); err != nil {
-				return nil, fmt.Errorf("ResolverManager::resolve error while deleting %s: %w", i.Object.Path(), err)
-			}
-			if err := p.upload(ctx, i.Object); err != nil {
-				return nil, fmt.Errorf("ResolverManager::resolve error while uploading %s: %w", i.Object.Path(), err)
-			}
-		case ActionUpload:
-			if err := p.upload(ctx, i.Object); err != nil {
-				return nil, fmt.Errorf("ResolverManager::resolve error while uploading %s: %w", i.Object.Path(), err)
-			}
-		case ActionDelete:
-			if err := p.delete(ctx, i.Object); err != nil {
-				return nil, fmt.Errorf("ResolverManager::resolve error while deleting %s: %w", i.Object.Path(), err)
-			}
-		}
-		return nil, nil
-	})
+	_, err := helpers.RunWorkers(ctx, p.workers, input, p.resolveOne)
 	if err != nil {
 		return fmt.Errorf("ResolverManager::resolve error while resolving: %w", err)
 	}
 	return nil
 }
 
+func isDeletePhase(phase ResolvePhase) bool {
+	for _, result := range phase.Results {
+		if result.Action != ActionDelete {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *ResolverManager) resolveAll(ctx context.Context, input []CompareResult) {
+	_, errs := helpers.RunAllWorkers(ctx, p.workers, input, p.resolveOne)
+	for _, e := range errs {
+		p.report.Failures = append(p.report.Failures, ResolveFailure{Result: e.Input, Err: e.Err})
+	}
+}
+
+func (p *ResolverManager) resolveOne(ctx context.Context, i CompareResult) (interface{}, error) {
+	switch i.Action {
+	case ActionChange:
+		if err := p.delete(ctx, i.Object); err != nil {
+			return nil, fmt.Errorf("ResolverManager::resolveOne error while deleting %s: %w", i.Object.Path(), err)
+		}
+		if err := p.upload(ctx, i.Object); err != nil {
+			return nil, fmt.Errorf("ResolverManager::resolveOne error while uploading %s: %w", i.Object.Path(), err)
+		}
+	case ActionUpload:
+		if err := p.upload(ctx, i.Object); err != nil {
+			return nil, fmt.Errorf("ResolverManager::resolveOne error while uploading %s: %w", i.Object.Path(), err)
+		}
+	case ActionDelete:
+		if err := p.delete(ctx, i.Object); err != nil {
+			return nil, fmt.Errorf("ResolverManager::resolveOne error while deleting %s: %w", i.Object.Path(), err)
+		}
+	case ActionRename:
+		if err := p.rename(ctx, i.From, i.Object); err != nil {
+			return nil, fmt.Errorf("ResolverManager::resolveOne error while renaming %s to %s: %w", i.From.Path(), i.Object.Path(), err)
+		}
+	}
+	return nil, nil
+}
+
 //func (p *ResolverManager) resolve(ctx context.Context, input []CompareResult) error {
 //	numWorkers := 5
 //	if len(input) < numWorkers {
@@ -167,7 +271,8 @@ func (p *ResolverManager) upload(ctx context.Context, object CompareObject) erro
 
 func (p *ResolverManager) delete(ctx context.Context, object CompareObject) error {
 	if object.IsDir() {
-		if err := p.remoteDeleter.DeleteDir(ctx, fmt.Sprintf("%s/%s", p.remotePath, object.Path())); err != nil {
+		// directory may contain files unknown to deployer (e.g. not uploaded by it), so only empty one is deleted
+		if err := p.remoteDeleter.DeleteEmptyDir(ctx, fmt.Sprintf("%s/%s", p.remotePath, object.Path())); err != nil {
 			return fmt.Errorf("ResolverManager::delete error while deleting directory %s: %w", object.Path(), err)
 		}
 		return nil
@@ -177,3 +282,10 @@ func (p *ResolverManager) delete(ctx context.Context, object CompareObject) erro
 	}
 	return nil
 }
+
+func (p *ResolverManager) rename(ctx context.Context, from CompareObject, to CompareObject) error {
+	if err := p.remoteRenamer.Rename(ctx, fmt.Sprintf("%s/%s", p.remotePath, from.Path()), fmt.Sprintf("%s/%s", p.remotePath, to.Path())); err != nil {
+		return fmt.Errorf("ResolverManager::rename error while renaming file %s: %w", from.Path(), err)
+	}
+	return nil
+}
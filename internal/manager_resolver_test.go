@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/filter"
+)
+
+func TestResolverManager_checkProtected(t *testing.T) {
+	tests := []struct {
+		name    string
+		protect []string
+		remote  []CompareObject
+		input   []CompareResult
+		wantErr bool
+	}{
+		{
+			name:    "Test 1",
+			protect: []string{"/uploads"},
+			input:   []CompareResult{{Object: NewFile("/uploads/a.jpg", "x"), Action: ActionChange}},
+			wantErr: true,
+		},
+		{
+			name:    "Test 2",
+			protect: []string{"/uploads"},
+			input:   []CompareResult{{Object: NewFolder("/uploads"), Action: ActionUpload}},
+			wantErr: false,
+		},
+		{
+			name:    "Test 3",
+			protect: []string{"/public/uploads"},
+			remote:  []CompareObject{NewFolder("/public"), NewFile("/public/uploads/a.jpg", "x")},
+			input:   []CompareResult{{Object: NewFolder("/public"), Action: ActionDelete}},
+			wantErr: true,
+		},
+		{
+			name:    "Test 4",
+			protect: []string{"/uploads"},
+			input:   []CompareResult{{Object: NewFile("/index.php", "x"), Action: ActionDelete}},
+			wantErr: false,
+		},
+		{
+			// remote listing is only the log file, it does not contain user uploads
+			name:    "Test 5",
+			protect: []string{"/public/uploads/"},
+			remote:  []CompareObject{NewFolder("/public"), NewFile("/public/index.php", "x")},
+			input:   []CompareResult{{Object: NewFolder("/public"), Action: ActionDelete}},
+			wantErr: true,
+		},
+		{
+			name:    "Test 6",
+			protect: []string{"*.log"},
+			remote:  []CompareObject{NewFolder("/var"), NewFile("/var/a.php", "x")},
+			input:   []CompareResult{{Object: NewFolder("/var"), Action: ActionDelete}},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns, err := filter.NewGitIgnoreFilter(tt.protect)
+			if err != nil {
+				t.Fatalf("NewGitIgnoreFilter() error = %v", err)
+			}
+			p := &ResolverManager{protected: newProtectFilter(tt.protect, patterns, tt.remote)}
+			if err := p.checkProtected(tt.input); (err != nil) != tt.wantErr {
+				t.Errorf("checkProtected() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolverManager_Resolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		continueOnErr bool
+		wantErr       bool
+		wantFailed    []string
+		wantUploaded  []string
+	}{
+		{
+			name:          "Test 1",
+			continueOnErr: true,
+			wantErr:       true,
+			wantFailed:    []string{"/missing.txt"},
+			wantUploaded:  []string{"/a.txt", "/b.txt"},
+		},
+		{
+			name:          "Test 2",
+			continueOnErr: false,
+			wantErr:       true,
+			wantFailed:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local, remote := t.TempDir(), t.TempDir()
+			for _, name := range []string{"a.txt", "b.txt"} {
+				if err := os.WriteFile(filepath.Join(local, name), []byte(name), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			factory := file_system.NewSystemFactory("0644", "0755")
+			protected, _ := filter.NewGitIgnoreFilter(nil)
+			p := NewResolverManager(
+				factory.Reader(),
+				factory.Writer(),
+				factory.Creator(),
+				factory.Deleter(),
+				factory.Renamer(),
+				protected,
+				nil,
+				1,
+				tt.continueOnErr,
+				local,
+				remote,
+			)
+			input := []CompareResult{
+				{Object: NewFile("/a.txt", "a"), Action: ActionUpload},
+				{Object: NewFile("/missing.txt", "m"), Action: ActionUpload},
+				{Object: NewFile("/b.txt", "b"), Action: ActionUpload},
+			}
+			if err := p.Resolve(context.Background(), input); (err != nil) != tt.wantErr {
+				t.Errorf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			failed := make([]string, 0)
+			for _, r := range p.Report().Results() {
+				failed = append(failed, r.Object.Path())
+			}
+			if !reflect.DeepEqual(failed, tt.wantFailed) {
+				t.Errorf("Report() = %v, want %v", failed, tt.wantFailed)
+			}
+			for _, path := range tt.wantUploaded {
+				if _, err := os.Stat(remote + path); err != nil {
+					t.Errorf("Resolve() did not upload %s: %v", path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestResolverManager_Resolve_deleteDirectories(t *testing.T) {
+	remote := t.TempDir()
+	// /owned contains file uploaded by user which is unknown to deployer
+	for _, path := range []string{"/owned/user.jpg", "/empty/a.txt"} {
+		if err := os.MkdirAll(filepath.Dir(remote+path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(remote+path, []byte(path), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	factory := file_system.NewSystemFactory("0644", "0755")
+	protected, _ := filter.NewGitIgnoreFilter(nil)
+	p := NewResolverManager(factory.Reader(), factory.Writer(), factory.Creator(), factory.Deleter(), factory.Renamer(),
+		protected, nil, 2, false, t.TempDir(), remote)
+	input := []CompareResult{
+		{Object: NewFolder("/owned"), Action: ActionDelete},
+		{Object: NewFolder("/empty"), Action: ActionDelete},
+		{Object: NewFile("/empty/a.txt", "a"), Action: ActionDelete},
+	}
+	if err := p.Resolve(context.Background(), input); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if _, err := os.Stat(remote + "/owned/user.jpg"); err != nil {
+		t.Errorf("Resolve() deleted unknown file: %v", err)
+	}
+	if _, err := os.Stat(remote + "/empty"); !os.IsNotExist(err) {
+		t.Errorf("Resolve() did not delete empty directory: %v", err)
+	}
+}
+
+func TestResolverManager_Resolve_failedRename(t *testing.T) {
+	local, remote := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(remote+"/old", 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(remote+"/old/removed.txt", []byte("removed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	factory := file_system.NewSystemFactory("0644", "0755")
+	protected, _ := filter.NewGitIgnoreFilter(nil)
+	p := NewResolverManager(factory.Reader(), factory.Writer(), factory.Creator(), factory.Deleter(), factory.Renamer(),
+		protected, nil, 2, true, local, remote)
+	// source of rename is missing, so rename fails
+	input := []CompareResult{
+		{Object: NewFolder("/new"), Action: ActionUpload},
+		{Object: NewFile("/new/a.txt", "a"), From: NewFile("/old/a.txt", "a"), Action: ActionRename},
+		{Object: NewFile("/old/removed.txt", "r"), Action: ActionDelete},
+		{Object: NewFolder("/old"), Action: ActionDelete},
+	}
+	if err := p.Resolve(context.Background(), input); err == nil {
+		t.Fatalf("Resolve() error = nil, want error")
+	}
+	got := make(map[string]bool)
+	for _, f := range p.Report().Failures {
+		got[f.Result.Object.Path()] = errors.Is(f.Err, ErrResolveSkipped)
+	}
+	want := map[string]bool{"/new/a.txt": false, "/old/removed.txt": true, "/old": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Report() = %v, want %v", got, want)
+	}
+	if _, err := os.Stat(remote + "/old/removed.txt"); err != nil {
+		t.Errorf("Resolve() deleted file after failed rename: %v", err)
+	}
+}
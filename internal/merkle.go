@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+// setDirectoryHashes return objects with hash of every directory computed from names and hashes of its children,
+// so equal directory hash means equal subtree
+func setDirectoryHashes(objects []CompareObject) []CompareObject {
+	children := make(map[string][]CompareObject)
+	dirs := make([]string, 0)
+	for _, o := range objects {
+		children[helpers.GetDirectoryPath(o.Path())] = append(children[helpers.GetDirectoryPath(o.Path())], o)
+		if o.IsDir() {
+			dirs = append(dirs, o.Path())
+		}
+	}
+	// deepest directories first, so hashes of subdirectories are known
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+	hashes := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		list := children[dir]
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Path() < list[j].Path()
+		})
+		var b strings.Builder
+		for _, child := range list {
+			hash := child.Hash()
+			if child.IsDir() {
+				hash = hashes[child.Path()]
+			}
+			b.WriteString(fmt.Sprintf("%s:%t:%s\n", child.Path(), child.IsDir(), hash))
+		}
+		hashes[dir] = helpers.HashBytes([]byte(b.String()))
+	}
+
+	res := make([]CompareObject, len(objects))
+	for i, o := range objects {
+		if o.IsDir() {
+			res[i] = &Folder{path: o.Path(), hash: hashes[o.Path()]}
+			continue
+		}
+		res[i] = o
+	}
+	return res
+}
+
+// DirectorySummary count of sync actions under top level directory
+type DirectorySummary struct {
+	Directory string
+	Actions   map[string]int
+}
+
+// summarizeDiff group file actions of diff by top level directory, files in root are grouped under "/"
+func summarizeDiff(diff []CompareResult) []DirectorySummary {
+	summaries := make(map[string]*DirectorySummary)
+	for _, r := range diff {
+		if r.Object.IsDir() {
+			continue
+		}
+		dir := "/"
+		if parts := strings.SplitN(strings.TrimPrefix(r.Object.Path(), "/"), "/", 2); len(parts) == 2 {
+			dir = "/" + parts[0]
+		}
+		if _, ok := summaries[dir]; !ok {
+			summaries[dir] = &DirectorySummary{Directory: dir, Actions: make(map[string]int)}
+		}
+		summaries[dir].Actions[r.Action]++
+	}
+	res := make([]DirectorySummary, 0, len(summaries))
+	for _, s := range summaries {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Directory < res[j].Directory
+	})
+	return res
+}
+
+func (s DirectorySummary) String() string {
+	parts := make([]string, 0, len(s.Actions))
+	for _, action := range []string{ActionUpload, ActionChange, ActionRename, ActionDelete} {
+		if count := s.Actions[action]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, action))
+		}
+	}
+	return fmt.Sprintf("%s: %s", s.Directory, strings.Join(parts, ", "))
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+func Test_setDirectoryHashes(t *testing.T) {
+	local := setDirectoryHashes([]CompareObject{
+		NewFolder("/vendor"),
+		NewFolder("/vendor/lib"),
+		NewFile("/vendor/lib/a.php", "a"),
+		NewFolder("/app"),
+		NewFile("/app/b.php", "b"),
+		NewFolder("/config"),
+		NewFile("/config/c.neon", "c"),
+	})
+	remote := setDirectoryHashes([]CompareObject{
+		NewFolder("/vendor"),
+		NewFolder("/vendor/lib"),
+		NewFile("/vendor/lib/a.php", "a"),
+		NewFile("/vendor/lib/old.php", "o"),
+		NewFolder("/app"),
+		NewFile("/app/b.php", "x"),
+		NewFolder("/config"),
+		NewFile("/config/c.neon", "c"),
+	})
+	tests := []struct {
+		name  string
+		path  string
+		equal bool
+	}{
+		{name: "Test 1", path: "/vendor", equal: false},
+		{name: "Test 2", path: "/app", equal: false},
+		{name: "Test 3", path: "/config", equal: true},
+	}
+	localMap := helpers.ConvertToMap(local)
+	remoteMap := helpers.ConvertToMap(remote)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := localMap[tt.path].Hash() == remoteMap[tt.path].Hash(); got != tt.equal {
+				t.Errorf("setDirectoryHashes() equal hash of %s = %v, want %v", tt.path, got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestCompareManager_CompareSkipsUnchangedDirectories(t *testing.T) {
+	objects := []CompareObject{
+		NewFolder("/vendor"),
+		NewFile("/vendor/a.php", "a"),
+		NewFile("/index.php", "i"),
+	}
+	source := helpers.ConvertToMap(setDirectoryHashes(objects))
+	remote := helpers.ConvertToMap(setDirectoryHashes(append(objects[:1:1], NewFile("/vendor/a.php", "a"), NewFile("/index.php", "x"))))
+	// files under directory with equal hash are not compared at all
+	remote["/vendor/a.php"] = NewFile("/vendor/a.php", "stale")
+
+	diff := NewCompareManager(DeletePolicyMirror, nil, true).Compare(source, remote)
+	if len(diff) != 1 || diff[0].Object.Path() != "/index.php" || diff[0].Action != ActionChange {
+		t.Errorf("Compare() = %v, want change of /index.php", diff)
+	}
+	summary := summarizeDiff(diff)
+	if len(summary) != 1 || summary[0].String() != "/: 1 change" {
+		t.Errorf("summarizeDiff() = %v, want [/: 1 change]", summary)
+	}
+}
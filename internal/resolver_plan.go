@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/filter"
+)
+
+// ResolvePhase group of operations which do not depend on each other and can run in parallel
+type ResolvePhase struct {
+	Name    string
+	Results []CompareResult
+}
+
+// UploadPhase files matched by filter are uploaded together, phase with nil filter contains all unmatched files
+type UploadPhase struct {
+	Name   string
+	Filter filter.Filter
+}
+
+// NewUploadPhases create upload phases from priority config
+func NewUploadPhases(priorities []PriorityConfig) ([]UploadPhase, error) {
+	res := make([]UploadPhase, 0, len(priorities))
+	for _, p := range priorities {
+		if len(p.Patterns) == 0 {
+			res = append(res, UploadPhase{Name: p.Name})
+			continue
+		}
+		f, err := filter.NewGitIgnoreFilter(p.Patterns)
+		if err != nil {
+			return nil, fmt.Errorf("NewUploadPhases error while creating filter for phase %s: %w", p.Name, err)
+		}
+		res = append(res, UploadPhase{Name: p.Name, Filter: f})
+	}
+	return res, nil
+}
+
+// NewResolvePlan order diff to phases, every phase depends only on previous phases:
+// - create directories parent-first (one phase per depth)
+// - upload, change and rename files, split to upload phases in given order
+// - delete files
+// - delete directories deepest-first (one phase per depth)
+func NewResolvePlan(input []CompareResult, uploadPhases []UploadPhase) []ResolvePhase {
+	createdDirs := make(map[int][]CompareResult)
+	deletedDirs := make(map[int][]CompareResult)
+	uploads := make([]CompareResult, 0, 100)
+	deletes := make([]CompareResult, 0, 100)
+	for _, result := range input {
+		depth := strings.Count(strings.Trim(result.Object.Path(), "/"), "/")
+		switch {
+		case result.Object.IsDir() && result.Action == ActionDelete:
+			deletedDirs[depth] = append(deletedDirs[depth], result)
+		case result.Object.IsDir():
+			createdDirs[depth] = append(createdDirs[depth], result)
+		case result.Action == ActionDelete:
+			deletes = append(deletes, result)
+		default:
+			uploads = append(uploads, result)
+		}
+	}
+
+	plan := make([]ResolvePhase, 0)
+	for _, depth := range sortedDepths(createdDirs, false) {
+		plan = append(plan, ResolvePhase{Name: fmt.Sprintf("create directories (depth %d)", depth), Results: createdDirs[depth]})
+	}
+	for _, phase := range splitUploads(uploads, uploadPhases) {
+		if len(phase.Results) > 0 {
+			plan = append(plan, phase)
+		}
+	}
+	if len(deletes) > 0 {
+		plan = append(plan, ResolvePhase{Name: "delete files", Results: deletes})
+	}
+	for _, depth := range sortedDepths(deletedDirs, true) {
+		plan = append(plan, ResolvePhase{Name: fmt.Sprintf("delete directories (depth %d)", depth), Results: deletedDirs[depth]})
+	}
+	return plan
+}
+
+// splitUploads assign every file to the first upload phase matching it, unmatched files go to phase without filter
+// or to extra phase after all phases when there is no such phase
+func splitUploads(uploads []CompareResult, uploadPhases []UploadPhase) []ResolvePhase {
+	res := make([]ResolvePhase, 0, len(uploadPhases)+1)
+	defaultPhase := -1
+	for i, p := range uploadPhases {
+		res = append(res, ResolvePhase{Name: fmt.Sprintf("upload files (%s)", p.Name)})
+		if p.Filter == nil && defaultPhase < 0 {
+			defaultPhase = i
+		}
+	}
+	if defaultPhase < 0 {
+		res = append(res, ResolvePhase{Name: "upload files"})
+		defaultPhase = len(res) - 1
+	}
+	for _, u := range uploads {
+		phase := defaultPhase
+		for i, p := range uploadPhases {
+			if p.Filter != nil && p.Filter.Contain(u.Object.Path(), false) {
+				phase = i
+				break
+			}
+		}
+		res[phase].Results = append(res[phase].Results, u)
+	}
+	return res
+}
+
+func sortedDepths(groups map[int][]CompareResult, deepestFirst bool) []int {
+	res := make([]int, 0, len(groups))
+	for depth := range groups {
+		res = append(res, depth)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if deepestFirst {
+			return res[i] > res[j]
+		}
+		return res[i] < res[j]
+	})
+	return res
+}
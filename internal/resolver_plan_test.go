@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewResolvePlan(t *testing.T) {
+	input := []CompareResult{
+		{Object: NewFolder("/old"), Action: ActionDelete},
+		{Object: NewFile("/app/b/index.php", "a"), Action: ActionUpload},
+		{Object: NewFolder("/app/b"), Action: ActionUpload},
+		{Object: NewFile("/old.php", "b"), Action: ActionDelete},
+		{Object: NewFolder("/old/sub"), Action: ActionDelete},
+		{Object: NewFolder("/app"), Action: ActionUpload},
+		{Object: NewFile("/config.php", "c"), Action: ActionChange},
+	}
+	want := [][]string{
+		{"/app"},
+		{"/app/b"},
+		{"/app/b/index.php", "/config.php"},
+		{"/old.php"},
+		{"/old/sub"},
+		{"/old"},
+	}
+	got := make([][]string, 0)
+	for _, phase := range NewResolvePlan(input, nil) {
+		paths := make([]string, 0, len(phase.Results))
+		for _, r := range phase.Results {
+			paths = append(paths, r.Object.Path())
+		}
+		got = append(got, paths)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewResolvePlan() = %v, want %v", got, want)
+	}
+}
+
+func TestNewResolvePlan_UploadPhases(t *testing.T) {
+	input := []CompareResult{
+		{Object: NewFile("/index.php", "a"), Action: ActionChange},
+		{Object: NewFile("/templates/layout.latte", "b"), Action: ActionUpload},
+		{Object: NewFile("/vendor/autoload.php", "c"), Action: ActionChange},
+		{Object: NewFile("/app/Model.php", "d"), Action: ActionUpload},
+		{Object: NewFile("/app/Old.php", "e"), Action: ActionDelete},
+	}
+	phases, err := NewUploadPhases([]PriorityConfig{
+		{Name: "libraries", Patterns: []string{"/vendor/", "/app/"}},
+		{Name: "other"},
+		{Name: "entry points", Patterns: []string{"/index.php", ".htaccess"}},
+	})
+	if err != nil {
+		t.Fatalf("NewUploadPhases() error = %v", err)
+	}
+	want := [][]string{
+		{"/vendor/autoload.php", "/app/Model.php"},
+		{"/templates/layout.latte"},
+		{"/index.php"},
+		{"/app/Old.php"},
+	}
+	got := make([][]string, 0)
+	for _, phase := range NewResolvePlan(input, phases) {
+		paths := make([]string, 0, len(phase.Results))
+		for _, r := range phase.Results {
+			paths = append(paths, r.Object.Path())
+		}
+		got = append(got, paths)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewResolvePlan() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bednarradek/php-deployer/pkg/throttle"
+)
+
+const (
+	MaxUploadRateEnv           = "DEPLOYER_MAX_UPLOAD_RATE"
+	MaxConnectionUploadRateEnv = "DEPLOYER_MAX_CONNECTION_UPLOAD_RATE"
+)
+
+// uploadRates return global and per connection upload rate in bytes per second, environment variables take precedence over config
+func uploadRates(sync FtpSyncConfig) (int64, int64, error) {
+	global, err := throttle.ParseRate(rateValue(MaxUploadRateEnv, sync.MaxUploadRate))
+	if err != nil {
+		return 0, 0, fmt.Errorf("uploadRates error while parsing max upload rate: %w", err)
+	}
+	connection, err := throttle.ParseRate(rateValue(MaxConnectionUploadRateEnv, sync.MaxConnectionUploadRate))
+	if err != nil {
+		return 0, 0, fmt.Errorf("uploadRates error while parsing max connection upload rate: %w", err)
+	}
+	return global, connection, nil
+}
+
+func rateValue(env string, value string) string {
+	if v, ok := os.LookupEnv(env); ok {
+		return v
+	}
+	return value
+}
+
+func formatRate(rate int64) string {
+	if rate <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d B/s", rate)
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"testing"
+)
+
+func Test_uploadRates(t *testing.T) {
+	tests := []struct {
+		name           string
+		sync           FtpSyncConfig
+		env            string
+		wantGlobal     int64
+		wantConnection int64
+		wantErr        bool
+	}{
+		{
+			name:           "Test 1",
+			sync:           FtpSyncConfig{MaxUploadRate: "2MB/s", MaxConnectionUploadRate: "512KB/s"},
+			wantGlobal:     2 * 1024 * 1024,
+			wantConnection: 512 * 1024,
+		},
+		{
+			name:           "Test 2",
+			sync:           FtpSyncConfig{MaxUploadRate: "2MB/s", MaxConnectionUploadRate: "512KB/s"},
+			env:            "1MB/s",
+			wantGlobal:     1024 * 1024,
+			wantConnection: 512 * 1024,
+		},
+		{
+			name:    "Test 3",
+			sync:    FtpSyncConfig{},
+			env:     "fast",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv(MaxUploadRateEnv, tt.env)
+			}
+			global, connection, err := uploadRates(tt.sync)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("uploadRates() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if global != tt.wantGlobal || connection != tt.wantConnection {
+				t.Errorf("uploadRates() = %v, %v, want %v, %v", global, connection, tt.wantGlobal, tt.wantConnection)
+			}
+		})
+	}
+}
@@ -7,6 +7,8 @@ type Deployer interface {
 type CompareResult struct {
 	Object CompareObject
 	Action string
+	// From is remote object moved to Object path by ActionRename
+	From CompareObject
 }
 
 type CompareObject interface {
@@ -43,6 +45,8 @@ func (f File) GetKey() string {
 
 type Folder struct {
 	path string
+	// hash computed from children, see setDirectoryHashes
+	hash string
 }
 
 func NewFolder(path string) *Folder {
@@ -58,7 +62,7 @@ func (f Folder) IsDir() bool {
 }
 
 func (f Folder) Hash() string {
-	return ""
+	return f.hash
 }
 
 func (f Folder) GetKey() string {
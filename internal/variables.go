@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/generator"
+	"github.com/bednarradek/php-deployer/pkg/git"
+	"github.com/bednarradek/php-deployer/pkg/secret"
+)
+
+const releaseFormat = "20060102150405"
+
+// names of variables in deploy namespace
+var deployVariableNames = []string{"release", "timestamp", "commit", "branch", "tag", "config", "host"}
+
+// loadVariables add static variables from env files and config to generator,
+// relative env files are resolved against directory of config
+// precedence from lowest: env files (in order), config vars, process environment
+func loadVariables(ctx context.Context, g *generator.EnvironmentGenerator, reader file_system.Reader, config *FtpConfig, configPath string) error {
+	variables := make(map[string]string)
+	for _, envFile := range config.EnvFiles {
+		envFile = configRelativePath(configPath, envFile)
+		content, err := reader.Read(ctx, envFile)
+		if err != nil {
+			return fmt.Errorf("loadVariables error while reading env file %s: %w", envFile, err)
+		}
+		res, err := generator.ParseDotEnv(content)
+		if err != nil {
+			return fmt.Errorf("loadVariables error while parsing env file %s: %w", envFile, err)
+		}
+		for k, v := range res {
+			variables[k] = v
+		}
+	}
+	for k, v := range config.Vars {
+		variables[k] = v
+	}
+	for k, v := range variables {
+		g.SetDefault(k, v)
+	}
+	return nil
+}
+
+// loadSecrets decrypt secrets file and resolve secrets from config, available in templates as {{.secrets.<name>}}
+// and add template function {{ secret "scheme:reference" }}, relative secrets file is resolved against directory of config
+func loadSecrets(ctx context.Context, g *generator.EnvironmentGenerator, masker *secret.Masker, config *FtpConfig, configPath string) error {
+	resolver := secret.NewResolver(masker)
+	secrets := make(map[string]string, len(config.Secrets))
+	if config.SecretsFile != "" {
+		key, err := secret.LoadKey()
+		if err != nil {
+			return fmt.Errorf("loadSecrets error while loading key for secrets file: %w", err)
+		}
+		store, err := secret.ReadStore(configRelativePath(configPath, config.SecretsFile))
+		if err != nil {
+			return fmt.Errorf("loadSecrets error while reading secrets file: %w", err)
+		}
+		values, err := store.Decrypt(key)
+		if err != nil {
+			return fmt.Errorf("loadSecrets error while decrypting secrets file: %w", err)
+		}
+		for name, value := range values {
+			masker.Add(value)
+			secrets[name] = value
+		}
+	}
+	for name, reference := range config.Secrets {
+		value, err := resolver.Resolve(ctx, reference)
+		if err != nil {
+			return fmt.Errorf("loadSecrets error while resolving secret %s: %w", name, err)
+		}
+		secrets[name] = value
+	}
+	g.SetVariable("secrets", secrets)
+	g.AddFunction("secret", func(reference string) (string, error) {
+		return resolver.Resolve(ctx, reference)
+	})
+	return nil
+}
+
+// configRelativePath resolve relative path against directory of config instead of working directory
+func configRelativePath(configPath string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(configPath), path)
+}
+
+// newDeployVariables create variables available in templates as {{.deploy.<name>}}
+func newDeployVariables(ctx context.Context, config *FtpConfig, configPath string, started time.Time) map[string]string {
+	info := git.ReadInfo(ctx, config.Sync.Source)
+	return map[string]string{
+		"release":   started.UTC().Format(releaseFormat),
+		"timestamp": started.UTC().Format(time.RFC3339),
+		"commit":    info.Commit,
+		"branch":    info.Branch,
+		"tag":       info.Tag,
+		"config":    filepath.Base(configPath),
+		"host":      "",
+	}
+}
+
+// setSyncVariables set variables available in templates as {{.sync.<name>}} from sync diff
+func setSyncVariables(variables map[string]string, diff []CompareResult) {
+	counts := map[string]int{ActionUpload: 0, ActionChange: 0, ActionDelete: 0, ActionRename: 0}
+	for _, result := range diff {
+		if result.Object.IsDir() {
+			continue
+		}
+		counts[result.Action]++
+	}
+	variables["uploaded"] = strconv.Itoa(counts[ActionUpload])
+	variables["changed"] = strconv.Itoa(counts[ActionChange])
+	variables["deleted"] = strconv.Itoa(counts[ActionDelete])
+	variables["renamed"] = strconv.Itoa(counts[ActionRename])
+	variables["total"] = strconv.Itoa(counts[ActionUpload] + counts[ActionChange] + counts[ActionDelete] + counts[ActionRename])
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bednarradek/php-deployer/pkg/file_system"
+	"github.com/bednarradek/php-deployer/pkg/generator"
+	"github.com/bednarradek/php-deployer/pkg/secret"
+)
+
+func Test_loadVariables(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "env"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"env/.env":            "FROM_ENV=env\nOVERRIDDEN_FILE=env\nOVERRIDDEN_VAR=env\nOVERRIDDEN_PROCESS=env\n",
+		"env/.env.production": "OVERRIDDEN_FILE=production\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("OVERRIDDEN_PROCESS", "process")
+	config := &FtpConfig{
+		// relative to directory of config, not to working directory
+		EnvFiles: []string{"env/.env", filepath.Join(dir, "env/.env.production")},
+		Vars:     map[string]string{"OVERRIDDEN_VAR": "var"},
+	}
+	g := generator.NewEnvironmentGenerator()
+	if err := loadVariables(context.Background(), g, file_system.NewSystemReader(), config, filepath.Join(dir, "deploy.json")); err != nil {
+		t.Fatalf("loadVariables() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "Test 1", template: "{{.FROM_ENV}}", want: "env"},
+		{name: "Test 2", template: "{{.OVERRIDDEN_FILE}}", want: "production"},
+		{name: "Test 3", template: "{{.OVERRIDDEN_VAR}}", want: "var"},
+		{name: "Test 4", template: "{{.OVERRIDDEN_PROCESS}}", want: "process"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Generate(context.Background(), []byte(tt.template))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Generate() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadSecrets(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(secret.KeyEnv, "passphrase")
+	store, err := secret.NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set([]byte("passphrase"), "FTP_PASSWORD", "password"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Write(filepath.Join(dir, "secrets.json")); err != nil {
+		t.Fatal(err)
+	}
+	// relative to directory of config, not to working directory
+	config := &FtpConfig{SecretsFile: "secrets.json"}
+	configPath := filepath.Join(dir, "deploy.json")
+
+	g := generator.NewEnvironmentGenerator()
+	if err := loadSecrets(context.Background(), g, secret.NewMasker(), config, configPath); err != nil {
+		t.Fatalf("loadSecrets() error = %v", err)
+	}
+	got, err := g.Generate(context.Background(), []byte("{{.secrets.FTP_PASSWORD}}"))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if string(got) != "password" {
+		t.Errorf("Generate() = %v, want %v", string(got), "password")
+	}
+
+	namespaces, err := NewEnvChecker(file_system.NewSystemReader(), g, configPath).namespaces(config)
+	if err != nil {
+		t.Fatalf("namespaces() error = %v", err)
+	}
+	if !namespaces["secrets"]["FTP_PASSWORD"] {
+		t.Errorf("namespaces() secrets = %v, want FTP_PASSWORD", namespaces["secrets"])
+	}
+}
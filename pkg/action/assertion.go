@@ -0,0 +1,67 @@
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+type Assertion interface {
+	Assert(response *HttpResponse) error
+}
+
+type ContainsAssertion struct {
+	value string
+}
+
+func NewContainsAssertion(value string) *ContainsAssertion {
+	return &ContainsAssertion{value: value}
+}
+
+func (c ContainsAssertion) Assert(response *HttpResponse) error {
+	if !bytes.Contains(response.Body, []byte(c.value)) {
+		return fmt.Errorf("ContainsAssertion::Assert response body does not contain %q", c.value)
+	}
+	return nil
+}
+
+type RegexAssertion struct {
+	regex *regexp.Regexp
+}
+
+func NewRegexAssertion(pattern string) (*RegexAssertion, error) {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("RegexAssertion::NewRegexAssertion error while compiling regex %s: %w", pattern, err)
+	}
+	return &RegexAssertion{regex: regex}, nil
+}
+
+func (r RegexAssertion) Assert(response *HttpResponse) error {
+	if !r.regex.Match(response.Body) {
+		return fmt.Errorf("RegexAssertion::Assert response body does not match %s", r.regex.String())
+	}
+	return nil
+}
+
+type JsonPathAssertion struct {
+	path     string
+	expected string
+}
+
+func NewJsonPathAssertion(path string, expected string) *JsonPathAssertion {
+	return &JsonPathAssertion{path: path, expected: expected}
+}
+
+func (j JsonPathAssertion) Assert(response *HttpResponse) error {
+	value, err := helpers.JsonPathString(response.Body, j.path)
+	if err != nil {
+		return fmt.Errorf("JsonPathAssertion::Assert error while reading path %s: %w", j.path, err)
+	}
+	if value != j.expected {
+		return fmt.Errorf("JsonPathAssertion::Assert value of path %s is %q, expected %q", j.path, value, j.expected)
+	}
+	return nil
+}
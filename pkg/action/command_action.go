@@ -0,0 +1,39 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+type CommandAction struct {
+	command string
+	args    []string
+	dir     string
+}
+
+func NewCommandAction(command string, args []string, dir string) *CommandAction {
+	return &CommandAction{command: command, args: args, dir: dir}
+}
+
+func (c *CommandAction) Do(ctx context.Context) error {
+	_, err := c.Call(ctx)
+	return err
+}
+
+// CommandAction::Call run local command and return its stdout
+func (c *CommandAction) Call(ctx context.Context) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, c.command, c.args...)
+	cmd.Dir = c.dir
+	cmd.Env = os.Environ()
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("CommandAction::Call error while running command %s: %w, stderr is: %s", c.command, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
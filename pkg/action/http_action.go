@@ -1,47 +1,149 @@
 package action
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
+type HttpResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
 type HttpAction struct {
 	url     string
 	method  string
 	headers map[string]string
-	body    io.Reader
+	body    []byte
+	options HttpOptions
 }
 
-func NewHttpAction(url string, method string, headers map[string]string, body io.Reader) *HttpAction {
-	return &HttpAction{url: url, method: method, headers: headers, body: body}
+func NewHttpAction(url string, method string, headers map[string]string, body []byte, options HttpOptions) *HttpAction {
+	return &HttpAction{url: url, method: method, headers: headers, body: body, options: options}
 }
 
 func (h *HttpAction) Do(ctx context.Context) error {
-	request, err := http.NewRequestWithContext(ctx, h.method, h.url, h.body)
+	_, err := h.Call(ctx)
+	return err
+}
+
+// HttpAction::Call call http request with retry and poll policy and return the first successful response
+func (h *HttpAction) Call(ctx context.Context) (*HttpResponse, error) {
+	client, err := h.client()
+	if err != nil {
+		return nil, fmt.Errorf("HttpAction::Call error while creating client for %s: %w", h.url, err)
+	}
+
+	if h.options.Poll.Enabled() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.options.Poll.Timeout)
+		defer cancel()
+	}
+
+	for attempt := 1; ; attempt++ {
+		request, err := h.request(ctx)
+		if err != nil {
+			return nil, err
+		}
+		response, err := h.call(client, request)
+		if err == nil {
+			return response, nil
+		}
+		delay, ok := h.nextDelay(attempt)
+		if !ok {
+			return nil, err
+		}
+		logrus.Warningf("HTTP request %s %s failed (attempt %d), retrying in %s: %s", h.method, h.url, attempt, delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("HttpAction::Call gave up calling url %s after %d attempts: %w", h.url, attempt, err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (h *HttpAction) nextDelay(attempt int) (time.Duration, bool) {
+	if h.options.Poll.Enabled() {
+		if h.options.Poll.Interval > 0 {
+			return h.options.Poll.Interval, true
+		}
+		return defaultPollInterval, true
+	}
+	if attempt > h.options.Retry.Attempts {
+		return 0, false
+	}
+	if h.options.Retry.Delay > 0 {
+		return h.options.Retry.Delay, true
+	}
+	return defaultRetryDelay, true
+}
+
+func (h *HttpAction) client() (*http.Client, error) {
+	timeout := h.options.Timeout
+	if timeout <= 0 {
+		timeout = defaultHttpTimeout
+	}
+	tlsConfig, err := h.options.Tls.config()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		return &http.Client{Timeout: timeout}, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+func (h *HttpAction) request(ctx context.Context) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, h.method, h.url, bytes.NewReader(h.body))
+	if err != nil {
+		return nil, fmt.Errorf("HttpAction::request error while creating request %s: %w", h.url, err)
+	}
 	for k, v := range h.headers {
 		request.Header.Add(k, v)
 	}
-	if err != nil {
-		return fmt.Errorf("HttpAction::Do error while creating request %s: %w", h.url, err)
+	if h.options.Auth != nil {
+		h.options.Auth.Apply(request)
 	}
-	response, err := http.DefaultClient.Do(request)
+	return request, nil
+}
+
+func (h *HttpAction) call(client *http.Client, request *http.Request) (*HttpResponse, error) {
+	response, err := client.Do(request)
 	if err != nil {
-		return fmt.Errorf("HttpAction::Do error while calling url %s: %w", h.url, err)
+		return nil, fmt.Errorf("HttpAction::call error while calling url %s: %w", h.url, err)
 	}
 	defer func() {
 		_ = response.Body.Close()
 	}()
+	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return fmt.Errorf("HttpAction::Do error while calling url %s: %w", h.url, err)
+		return nil, fmt.Errorf("HttpAction::call error while reading response of url %s: %w", h.url, err)
 	}
-	if response.StatusCode/100 != 2 {
-		r, err := io.ReadAll(response.Body)
-		if err != nil {
-			return fmt.Errorf("HttpAction::Do error while calling url %s: status code is %d", h.url, response.StatusCode)
+	if !h.expectedStatus(response.StatusCode) {
+		return nil, fmt.Errorf("HttpAction::call error while calling url %s: status code is %d, response body is: %s", h.url, response.StatusCode, string(body))
+	}
+	result := &HttpResponse{StatusCode: response.StatusCode, Header: response.Header, Body: body}
+	for _, assertion := range h.options.Assertions {
+		if err := assertion.Assert(result); err != nil {
+			return nil, fmt.Errorf("HttpAction::call assertion failed for url %s: %w", h.url, err)
 		}
-		return fmt.Errorf("HttpAction::Do error while calling url %s: status code is %d, response body is: %s", h.url, response.StatusCode, string(r))
 	}
-	return nil
+	return result, nil
+}
+
+func (h *HttpAction) expectedStatus(code int) bool {
+	if len(h.options.ExpectedStatus) == 0 {
+		return code/100 == 2
+	}
+	return slices.Contains(h.options.ExpectedStatus, code)
 }
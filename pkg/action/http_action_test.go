@@ -0,0 +1,137 @@
+package action
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHttpAction_Call(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   func(calls int32, w http.ResponseWriter, r *http.Request)
+		url       string
+		headers   map[string]string
+		options   HttpOptions
+		wantBody  string
+		wantCalls int32
+		wantErr   bool
+	}{
+		{
+			name: "Test 1",
+			handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+				if calls < 3 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			},
+			options:   HttpOptions{Retry: RetryPolicy{Attempts: 3, Delay: time.Millisecond}},
+			wantBody:  "ok",
+			wantCalls: 3,
+		},
+		{
+			name: "Test 2",
+			handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			options:   HttpOptions{Retry: RetryPolicy{Attempts: 1, Delay: time.Millisecond}},
+			wantCalls: 2,
+			wantErr:   true,
+		},
+		{
+			name: "Test 3",
+			handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+				if calls < 4 {
+					_, _ = w.Write([]byte(`{"status": "deploying"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"status": "ready"}`))
+			},
+			options: HttpOptions{
+				Poll:       PollPolicy{Timeout: 5 * time.Second, Interval: time.Millisecond},
+				Assertions: []Assertion{NewJsonPathAssertion("status", "ready")},
+			},
+			wantBody:  `{"status": "ready"}`,
+			wantCalls: 4,
+		},
+		{
+			name: "Test 4",
+			handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			options:   HttpOptions{ExpectedStatus: []int{http.StatusOK}},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "Test 5",
+			handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+				user, password, ok := r.BasicAuth()
+				if !ok || user != "deploy" || password != "secret" || r.Header.Get("X-Release") != "42" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte("basic"))
+			},
+			headers:   map[string]string{"X-Release": "42"},
+			options:   HttpOptions{Auth: NewBasicAuth("deploy", "secret")},
+			wantBody:  "basic",
+			wantCalls: 1,
+		},
+		{
+			name: "Test 6",
+			handler: func(calls int32, w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte("bearer"))
+			},
+			options:   HttpOptions{Auth: NewBearerAuth("token")},
+			wantBody:  "bearer",
+			wantCalls: 1,
+		},
+		{
+			name:    "Test 7",
+			url:     "http://[::1]:namedport",
+			options: HttpOptions{Retry: RetryPolicy{Attempts: 3, Delay: time.Millisecond}},
+			wantErr: true,
+		},
+		{
+			name:    "Test 8",
+			url:     "://missing-scheme",
+			options: HttpOptions{Poll: PollPolicy{Timeout: time.Second, Interval: time.Millisecond}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(calls.Add(1), w, r)
+			}))
+			defer server.Close()
+			url := tt.url
+			if url == "" {
+				url = server.URL
+			}
+			response, err := NewHttpAction(url, http.MethodGet, tt.headers, nil, tt.options).Call(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HttpAction::Call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("HttpAction::Call() calls = %v, want %v", got, tt.wantCalls)
+			}
+			if err != nil {
+				return
+			}
+			if got := string(response.Body); got != tt.wantBody {
+				t.Errorf("HttpAction::Call() body = %v, want %v", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package action
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const defaultHttpTimeout = 30 * time.Second
+const defaultRetryDelay = time.Second
+const defaultPollInterval = 5 * time.Second
+
+type HttpOptions struct {
+	// Timeout of a single request, default is 30 seconds
+	Timeout time.Duration
+	// Retry repeats failed request given number of times
+	Retry RetryPolicy
+	// Poll repeats failed request until it succeeds or poll timeout is reached
+	Poll PollPolicy
+	// ExpectedStatus list of accepted status codes, any 2xx status code is accepted when empty
+	ExpectedStatus []int
+	Assertions     []Assertion
+	Auth           Auth
+	Tls            TlsOptions
+}
+
+type RetryPolicy struct {
+	Attempts int
+	Delay    time.Duration
+}
+
+type PollPolicy struct {
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+func (p PollPolicy) Enabled() bool {
+	return p.Timeout > 0
+}
+
+type Auth interface {
+	Apply(request *http.Request)
+}
+
+type BasicAuth struct {
+	user     string
+	password string
+}
+
+func NewBasicAuth(user string, password string) *BasicAuth {
+	return &BasicAuth{user: user, password: password}
+}
+
+func (b BasicAuth) Apply(request *http.Request) {
+	request.SetBasicAuth(b.user, b.password)
+}
+
+type BearerAuth struct {
+	token string
+}
+
+func NewBearerAuth(token string) *BearerAuth {
+	return &BearerAuth{token: token}
+}
+
+func (b BearerAuth) Apply(request *http.Request) {
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", b.token))
+}
+
+type TlsOptions struct {
+	CaFile   string
+	CertFile string
+	KeyFile  string
+	Insecure bool
+}
+
+func (t TlsOptions) config() (*tls.Config, error) {
+	if t.CaFile == "" && t.CertFile == "" && !t.Insecure {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: t.Insecure}
+	if t.CaFile != "" {
+		ca, err := os.ReadFile(t.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("TlsOptions::config error while reading CA file %s: %w", t.CaFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("TlsOptions::config CA file %s does not contain any PEM certificate", t.CaFile)
+		}
+		config.RootCAs = pool
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("TlsOptions::config error while loading client certificate %s: %w", t.CertFile, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
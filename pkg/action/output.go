@@ -0,0 +1,81 @@
+package action
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+// Extractor extract output value from action result (http response body and headers or command stdout)
+type Extractor interface {
+	Extract(body []byte, header http.Header) (string, error)
+}
+
+type JsonPathExtractor struct {
+	path string
+}
+
+func NewJsonPathExtractor(path string) *JsonPathExtractor {
+	return &JsonPathExtractor{path: path}
+}
+
+func (j JsonPathExtractor) Extract(body []byte, _ http.Header) (string, error) {
+	value, err := helpers.JsonPathString(body, j.path)
+	if err != nil {
+		return "", fmt.Errorf("JsonPathExtractor::Extract error while reading path %s: %w", j.path, err)
+	}
+	return value, nil
+}
+
+type RegexExtractor struct {
+	regex *regexp.Regexp
+}
+
+func NewRegexExtractor(pattern string) (*RegexExtractor, error) {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("RegexExtractor::NewRegexExtractor error while compiling regex %s: %w", pattern, err)
+	}
+	return &RegexExtractor{regex: regex}, nil
+}
+
+// RegexExtractor::Extract return first capture group or whole match when regex has no group
+func (r RegexExtractor) Extract(body []byte, _ http.Header) (string, error) {
+	match := r.regex.FindSubmatch(body)
+	if match == nil {
+		return "", fmt.Errorf("RegexExtractor::Extract output does not match %s", r.regex.String())
+	}
+	if len(match) > 1 {
+		return string(match[1]), nil
+	}
+	return string(match[0]), nil
+}
+
+type HeaderExtractor struct {
+	name string
+}
+
+func NewHeaderExtractor(name string) *HeaderExtractor {
+	return &HeaderExtractor{name: name}
+}
+
+func (h HeaderExtractor) Extract(_ []byte, header http.Header) (string, error) {
+	if header == nil || len(header.Values(h.name)) == 0 {
+		return "", fmt.Errorf("HeaderExtractor::Extract header %s is missing", h.name)
+	}
+	return header.Get(h.name), nil
+}
+
+type StdoutExtractor struct {
+}
+
+func NewStdoutExtractor() *StdoutExtractor {
+	return &StdoutExtractor{}
+}
+
+func (s StdoutExtractor) Extract(body []byte, _ http.Header) (string, error) {
+	return strings.TrimSpace(string(body)), nil
+}
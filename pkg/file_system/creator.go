@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/bednarradek/php-deployer/pkg/ftp"
@@ -13,31 +15,64 @@ type Creator interface {
 	CreateDir(ctx context.Context, path string) error
 }
 
+type SystemCreator struct {
+	defaultMode string
+}
+
+func NewSystemCreator(defaultMode string) *SystemCreator {
+	return &SystemCreator{defaultMode: defaultMode}
+}
+
+func (s SystemCreator) CreateDir(_ context.Context, path string) error {
+	mode, err := strconv.ParseUint(s.defaultMode, 8, 32)
+	if err != nil {
+		return fmt.Errorf("SystemCreator::CreateDir error while parsing mode %s: %w", s.defaultMode, err)
+	}
+	if err := os.MkdirAll(path, os.FileMode(mode)); err != nil {
+		return fmt.Errorf("SystemCreator::CreateDir error while creating directory %s: %w", path, err)
+	}
+	return nil
+}
+
+// FtpCreator create directory with all its parents, directories known to exist are skipped
 type FtpCreator struct {
 	ftpConnection *ftp.Connection
 	defaultMode   string
+	dirCache      *DirCache
 }
 
-func NewFtpCreator(ftpConnection *ftp.Connection, defaultMode string) *FtpCreator {
-	return &FtpCreator{ftpConnection: ftpConnection, defaultMode: defaultMode}
+func NewFtpCreator(ftpConnection *ftp.Connection, defaultMode string, dirCache *DirCache) *FtpCreator {
+	return &FtpCreator{ftpConnection: ftpConnection, defaultMode: defaultMode, dirCache: dirCache}
 }
 
 func (f FtpCreator) CreateDir(ctx context.Context, path string) error {
 	split := strings.Split(path, "/")
 	for i := 0; i < len(split); i++ {
 		p := strings.Join(split[:i+1], "/")
-		if err := f.ftpConnection.MakeDir(ctx, p); err != nil {
-			if errors.Is(err, ftp.ErrorFtpPermissionDenied) {
-				continue
-			}
-			return fmt.Errorf("FtpCreator::CreateDir error while creating directory %s from %s: %w", p, path, err)
+		if p == "" {
+			continue
+		}
+		if err := f.dirCache.Ensure(p, func() error {
+			return f.createDir(ctx, p)
+		}); err != nil {
+			return fmt.Errorf("FtpCreator::CreateDir error while creating directory %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (f FtpCreator) createDir(ctx context.Context, p string) error {
+	if err := f.ftpConnection.MakeDir(ctx, p); err != nil {
+		if errors.Is(err, ftp.ErrorFtpPermissionDenied) {
+			return nil
 		}
-		if err := f.ftpConnection.Chmod(ctx, p, f.defaultMode); err != nil {
-			if errors.Is(err, ftp.ErrorFtpPermissionDenied) {
-				continue
-			}
-			return fmt.Errorf("FtpCreator::CreateDir error while changing mode of directory %s from %s: %w", p, path, err)
+		return fmt.Errorf("FtpCreator::createDir error while creating directory %s: %w", p, err)
+	}
+	if err := f.ftpConnection.Chmod(ctx, p, f.defaultMode); err != nil {
+		if errors.Is(err, ftp.ErrorFtpPermissionDenied) {
+			return nil
 		}
+		return fmt.Errorf("FtpCreator::createDir error while changing mode of directory %s: %w", p, err)
 	}
 	return nil
 }
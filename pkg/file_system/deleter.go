@@ -12,6 +12,8 @@ import (
 type Deleter interface {
 	Delete(ctx context.Context, path string) error
 	DeleteDir(ctx context.Context, path string) error
+	// DeleteEmptyDir delete directory only when it is empty, directory with content is kept
+	DeleteEmptyDir(ctx context.Context, path string) error
 }
 
 type SystemDeleter struct {
@@ -38,12 +40,30 @@ func (s SystemDeleter) DeleteDir(_ context.Context, path string) error {
 	return nil
 }
 
+func (s SystemDeleter) DeleteEmptyDir(_ context.Context, path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("SystemDeleter::DeleteEmptyDir error while reading directory %s: %w", path, err)
+	}
+	if len(entries) > 0 {
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("SystemDeleter::DeleteEmptyDir error while deleting directory %s: %w", path, err)
+	}
+	return nil
+}
+
 type FtpDeleter struct {
 	ftpConnection *ftp.Connection
+	dirCache      *DirCache
 }
 
-func NewFtpDeleter(ftpConnection *ftp.Connection) *FtpDeleter {
-	return &FtpDeleter{ftpConnection: ftpConnection}
+func NewFtpDeleter(ftpConnection *ftp.Connection, dirCache *DirCache) *FtpDeleter {
+	return &FtpDeleter{ftpConnection: ftpConnection, dirCache: dirCache}
 }
 
 func (f FtpDeleter) Delete(ctx context.Context, path string) error {
@@ -61,6 +81,7 @@ func (f FtpDeleter) Delete(ctx context.Context, path string) error {
 }
 
 func (f FtpDeleter) DeleteDir(ctx context.Context, path string) error {
+	f.dirCache.Remove(path)
 	if err := f.ftpConnection.RemoveDirRecur(ctx, path); err != nil {
 		if errors.Is(err, ftp.ErrorFtpPermissionDenied) {
 			return nil
@@ -69,3 +90,15 @@ func (f FtpDeleter) DeleteDir(ctx context.Context, path string) error {
 	}
 	return nil
 }
+
+// FtpDeleter::DeleteEmptyDir remove directory by RMD, directory which is not empty is refused by server and kept
+func (f FtpDeleter) DeleteEmptyDir(ctx context.Context, path string) error {
+	if err := f.ftpConnection.RemoveDir(ctx, path); err != nil {
+		if errors.Is(err, ftp.ErrorFtpPermissionDenied) {
+			return nil
+		}
+		return err
+	}
+	f.dirCache.Remove(path)
+	return nil
+}
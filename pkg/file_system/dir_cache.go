@@ -0,0 +1,71 @@
+package file_system
+
+import (
+	"path"
+	"strings"
+	"sync"
+)
+
+type dirState struct {
+	done chan struct{}
+	err  error
+}
+
+// DirCache concurrency-safe set of remote directories known to exist,
+// concurrent requests for the same directory wait for single creation
+type DirCache struct {
+	mu   sync.Mutex
+	dirs map[string]*dirState
+}
+
+func NewDirCache() *DirCache {
+	return &DirCache{dirs: make(map[string]*dirState)}
+}
+
+// DirCache::Add mark directory and all its parents as existing
+func (c *DirCache) Add(dir string) {
+	done := make(chan struct{})
+	close(done)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for d := path.Clean(dir); d != "/" && d != "."; d = path.Dir(d) {
+		c.dirs[d] = &dirState{done: done}
+	}
+}
+
+// DirCache::Remove forget directory and all its subdirectories
+func (c *DirCache) Remove(dir string) {
+	dir = path.Clean(dir)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for d := range c.dirs {
+		if d == dir || strings.HasPrefix(d, dir+"/") {
+			delete(c.dirs, d)
+		}
+	}
+}
+
+// DirCache::Ensure call create only when directory is not known yet, failed creation is forgotten so it can be retried
+func (c *DirCache) Ensure(dir string, create func() error) error {
+	dir = path.Clean(dir)
+	c.mu.Lock()
+	if state, ok := c.dirs[dir]; ok {
+		c.mu.Unlock()
+		<-state.done
+		return state.err
+	}
+	state := &dirState{done: make(chan struct{})}
+	c.dirs[dir] = state
+	c.mu.Unlock()
+
+	state.err = create()
+	close(state.done)
+	if state.err != nil {
+		c.mu.Lock()
+		if c.dirs[dir] == state {
+			delete(c.dirs, dir)
+		}
+		c.mu.Unlock()
+	}
+	return state.err
+}
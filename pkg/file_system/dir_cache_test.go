@@ -0,0 +1,79 @@
+package file_system
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDirCache_Ensure(t *testing.T) {
+	tests := []struct {
+		name      string
+		known     []string
+		removed   []string
+		dirs      []string
+		createErr error
+		want      int32
+	}{
+		{
+			name: "Test 1",
+			dirs: []string{"/www/app", "/www/app", "/www//app/", "/www/app"},
+			want: 1,
+		},
+		{
+			name:  "Test 2",
+			known: []string{"/www/app/temp"},
+			dirs:  []string{"/www", "/www/app", "/www/app/temp"},
+			want:  0,
+		},
+		{
+			name:    "Test 3",
+			known:   []string{"/www/app/temp"},
+			removed: []string{"/www/app"},
+			dirs:    []string{"/www", "/www/app", "/www/app/temp"},
+			want:    2,
+		},
+		{
+			name:      "Test 4",
+			dirs:      []string{"/www", "/www"},
+			createErr: errors.New("failed"),
+			want:      2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDirCache()
+			for _, d := range tt.known {
+				c.Add(d)
+			}
+			for _, d := range tt.removed {
+				c.Remove(d)
+			}
+			var created int32
+			wg := sync.WaitGroup{}
+			for _, d := range tt.dirs {
+				if tt.createErr != nil {
+					// sequential calls, failed creation must be retried
+					_ = c.Ensure(d, func() error {
+						atomic.AddInt32(&created, 1)
+						return tt.createErr
+					})
+					continue
+				}
+				wg.Add(1)
+				go func(d string) {
+					defer wg.Done()
+					_ = c.Ensure(d, func() error {
+						atomic.AddInt32(&created, 1)
+						return nil
+					})
+				}(d)
+			}
+			wg.Wait()
+			if created != tt.want {
+				t.Errorf("Ensure() created = %v, want %v", created, tt.want)
+			}
+		})
+	}
+}
@@ -1,47 +1,73 @@
 package file_system
 
-import "github.com/bednarradek/php-deployer/pkg/ftp"
+import (
+	"github.com/bednarradek/php-deployer/pkg/ftp"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+	"github.com/bednarradek/php-deployer/pkg/throttle"
+)
+
+// number of local directories listed concurrently
+const systemListWorkers = 10
 
 type FtpFactory struct {
 	connection        *ftp.Connection
 	defaultFileMode   string
 	defaultFolderMode string
+	listWorkers       int
+	throttle          *throttle.Throttle
+	dirCache          *DirCache
 }
 
 func NewFtpFactory(
 	connection *ftp.Connection,
 	defaultFileMode string,
 	defaultFolderMode string,
+	listWorkers int,
+	throttle *throttle.Throttle,
 ) *FtpFactory {
 	return &FtpFactory{
 		connection:        connection,
 		defaultFileMode:   defaultFileMode,
 		defaultFolderMode: defaultFolderMode,
+		listWorkers:       listWorkers,
+		throttle:          throttle,
+		dirCache:          NewDirCache(),
 	}
 }
 
+// FtpFactory::DirCache return cache of remote directories shared by creators and deleters of factory
+func (f *FtpFactory) DirCache() *DirCache {
+	return f.dirCache
+}
+
 func (f *FtpFactory) Creator() Creator {
-	return NewFtpCreator(f.connection, f.defaultFolderMode)
+	return NewFtpCreator(f.connection, f.defaultFolderMode, f.dirCache)
 }
 
 func (f *FtpFactory) Deleter() Deleter {
-	return NewFtpDeleter(f.connection)
+	return NewFtpDeleter(f.connection, f.dirCache)
 }
 
 func (f *FtpFactory) Lister() Lister {
 	return NewFtpLister(f.connection)
 }
 
+// FtpFactory::RecursiveLister return lister of whole tree, every directory listed in parallel uses its own pooled connection
 func (f *FtpFactory) RecursiveLister() Lister {
-	return NewRecursiveLister(f.Lister())
+	return NewRecursiveLister(f.Lister(), f.listWorkers)
 }
 
 func (f *FtpFactory) Reader() Reader {
 	return NewFtpReader(f.connection)
 }
 
-func (f *FtpFactory) HashReader() HashReader {
-	return NewStandardHashReader(f.Reader())
+// FtpFactory::HashReader return reader hashing on server when it supports algorithm, otherwise files are downloaded,
+// support is checked on first hashed file
+func (f *FtpFactory) HashReader(algorithm string) HashReader {
+	if algorithm == helpers.HashSizeMtime {
+		return NewUnknownHashReader()
+	}
+	return NewServerHashReader(f.connection, algorithm, NewStandardHashReader(f.Reader(), algorithm))
 }
 
 func (f *FtpFactory) CompressionReader() Reader {
@@ -49,7 +75,7 @@ func (f *FtpFactory) CompressionReader() Reader {
 }
 
 func (f *FtpFactory) Writer() Writer {
-	return NewFtpWriter(f.connection, f.defaultFileMode)
+	return NewFtpWriter(f.connection, f.defaultFileMode, f.throttle)
 }
 
 func (f *FtpFactory) CompressionWriter() Writer {
@@ -60,6 +86,10 @@ func (f *FtpFactory) ChangeModer() ChangeModer {
 	return NewFtpChangeModer(f.connection)
 }
 
+func (f *FtpFactory) Renamer() Renamer {
+	return NewFtpRenamer(f.connection)
+}
+
 type SystemFactory struct {
 	defaultFileMode   string
 	defaultFolderMode string
@@ -75,6 +105,10 @@ func NewSystemFactory(
 	}
 }
 
+func (s *SystemFactory) Creator() Creator {
+	return NewSystemCreator(s.defaultFolderMode)
+}
+
 func (s *SystemFactory) Deleter() Deleter {
 	return NewSystemDeleter()
 }
@@ -84,21 +118,28 @@ func (s *SystemFactory) Lister() Lister {
 }
 
 func (s *SystemFactory) RecursiveLister() Lister {
-	return NewRecursiveLister(s.Lister())
+	return NewRecursiveLister(s.Lister(), systemListWorkers)
 }
 
 func (s *SystemFactory) Reader() Reader {
 	return NewSystemReader()
 }
 
-func (s *SystemFactory) HashReader() HashReader {
-	return NewStandardHashReader(s.Reader())
+func (s *SystemFactory) HashReader(algorithm string) HashReader {
+	if algorithm == helpers.HashSizeMtime {
+		return NewStatHashReader()
+	}
+	return NewStandardHashReader(s.Reader(), algorithm)
 }
 
 func (s *SystemFactory) Writer() Writer {
 	return NewSystemWriter()
 }
 
+func (s *SystemFactory) Renamer() Renamer {
+	return NewSystemRenamer()
+}
+
 type LogFactory struct {
 	logPath string
 }
@@ -0,0 +1,121 @@
+package file_system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// hashCacheRacyWindow file modified this short before it was hashed may be modified again with the same mtime
+// (coarse mtime resolution of some file systems), so its hash is not cached
+const hashCacheRacyWindow = 3 * time.Second
+
+type HashCacheEntry struct {
+	Size      int64  `json:"size"`
+	ModTime   int64  `json:"modTime"`
+	Inode     uint64 `json:"inode,omitempty"`
+	Algorithm string `json:"algorithm,omitempty"`
+	Hash      string `json:"hash"`
+}
+
+// HashCache local file hashes keyed by path, entry is valid while size, mtime and inode of file and algorithm are unchanged
+type HashCache struct {
+	mu      sync.Mutex
+	entries map[string]HashCacheEntry
+	seen    map[string]HashCacheEntry
+}
+
+// LoadHashCache read cache file, missing file results in empty cache
+func LoadHashCache(path string) (*HashCache, error) {
+	cache := &HashCache{entries: make(map[string]HashCacheEntry), seen: make(map[string]HashCacheEntry)}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("HashCache::LoadHashCache error while reading cache file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(content, &cache.entries); err != nil {
+		return nil, fmt.Errorf("HashCache::LoadHashCache error while unmarshalling cache file %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// HashCache::Save write entries used since load, entries of deleted files are dropped
+func (c *HashCache) Save(path string) error {
+	c.mu.Lock()
+	b, err := json.Marshal(c.seen)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("HashCache::Save error while marshalling cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("HashCache::Save error while creating cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("HashCache::Save error while writing cache file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *HashCache) get(path string, stat HashCacheEntry) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || entry.Size != stat.Size || entry.ModTime != stat.ModTime || entry.Inode != stat.Inode || entry.Algorithm != stat.Algorithm {
+		return "", false
+	}
+	c.seen[path] = entry
+	return entry.Hash, true
+}
+
+func (c *HashCache) set(path string, entry HashCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = entry
+	c.seen[path] = entry
+}
+
+// CachedHashReader read hash of local file from cache and hash file only when its stat changed,
+// files modified within hashCacheRacyWindow before hashing are not cached
+type CachedHashReader struct {
+	cache     *HashCache
+	reader    HashReader
+	algorithm string
+	rehash    bool
+}
+
+// NewCachedHashReader create reader, with rehash all files are hashed again and cache is refreshed
+func NewCachedHashReader(cache *HashCache, reader HashReader, algorithm string, rehash bool) *CachedHashReader {
+	return &CachedHashReader{cache: cache, reader: reader, algorithm: algorithm, rehash: rehash}
+}
+
+func (c *CachedHashReader) ReadHash(ctx context.Context, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("CachedHashReader::ReadHash error while reading stat of file %s: %w", path, err)
+	}
+	stat := HashCacheEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Inode: inode(info), Algorithm: c.algorithm}
+	if !c.rehash {
+		if hash, ok := c.cache.get(path, stat); ok {
+			return hash, nil
+		}
+	}
+	hashed := time.Now()
+	hash, err := c.reader.ReadHash(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("CachedHashReader::ReadHash error while hashing file %s: %w", path, err)
+	}
+	if hashed.Sub(info.ModTime()) < hashCacheRacyWindow {
+		return hash, nil
+	}
+	stat.Hash = hash
+	c.cache.set(path, stat)
+	return hash, nil
+}
@@ -0,0 +1,110 @@
+package file_system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bednarradek/php-deployer/pkg/helpers"
+)
+
+type countingHashReader struct {
+	count int
+}
+
+func (c *countingHashReader) ReadHash(_ context.Context, path string) (string, error) {
+	c.count++
+	content, err := os.ReadFile(path)
+	return string(content), err
+}
+
+func TestCachedHashReader_ReadHash(t *testing.T) {
+	tests := []struct {
+		name      string
+		racy      bool
+		modify    bool
+		rehash    bool
+		wantCount int
+		wantHash  string
+	}{
+		{
+			name:      "Test 1",
+			wantCount: 0,
+			wantHash:  "a",
+		},
+		{
+			name:      "Test 2",
+			modify:    true,
+			wantCount: 1,
+			wantHash:  "bb",
+		},
+		{
+			name:      "Test 3",
+			rehash:    true,
+			wantCount: 1,
+			wantHash:  "a",
+		},
+		{
+			name:      "Test 4",
+			racy:      true,
+			wantCount: 1,
+			wantHash:  "a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "index.php")
+			cacheFile := filepath.Join(dir, "cache", "hashes.json")
+			if err := os.WriteFile(file, []byte("a"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			// file modified right before hashing may change again without mtime change, so it is not cached
+			if !tt.racy {
+				past := time.Now().Add(-time.Hour)
+				if err := os.Chtimes(file, past, past); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// first deploy fills cache
+			cache, err := LoadHashCache(cacheFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewCachedHashReader(cache, &countingHashReader{}, helpers.HashSha256, false).ReadHash(context.Background(), file); err != nil {
+				t.Fatal(err)
+			}
+			if err := cache.Save(cacheFile); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.modify {
+				if err := os.WriteFile(file, []byte("bb"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cache, err = LoadHashCache(cacheFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader := &countingHashReader{}
+			hash, err := NewCachedHashReader(cache, reader, helpers.HashSha256, tt.rehash).ReadHash(context.Background(), file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reader.count != tt.wantCount {
+				t.Errorf("ReadHash() hashed %d times, want %d", reader.count, tt.wantCount)
+			}
+			if hash != tt.wantHash {
+				t.Errorf("ReadHash() = %v, want %v", hash, tt.wantHash)
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package file_system
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sync/atomic"
 
+	"github.com/bednarradek/php-deployer/pkg/ftp"
 	"github.com/bednarradek/php-deployer/pkg/helpers"
 )
 
@@ -11,12 +15,14 @@ type HashReader interface {
 	ReadHash(ctx context.Context, path string) (string, error)
 }
 
+// StandardHashReader read whole file and hash its content
 type StandardHashReader struct {
-	reader Reader
+	reader    Reader
+	algorithm string
 }
 
-func NewStandardHashReader(reader Reader) *StandardHashReader {
-	return &StandardHashReader{reader: reader}
+func NewStandardHashReader(reader Reader, algorithm string) *StandardHashReader {
+	return &StandardHashReader{reader: reader, algorithm: algorithm}
 }
 
 func (s *StandardHashReader) ReadHash(ctx context.Context, path string) (string, error) {
@@ -24,7 +30,69 @@ func (s *StandardHashReader) ReadHash(ctx context.Context, path string) (string,
 	if err != nil {
 		return "", fmt.Errorf("StandardHashReader::ReadHash error while reading file %s: %w", path, err)
 	}
-	return helpers.HashBytes(content), nil
+	hash, err := helpers.HashBytesWith(s.algorithm, content)
+	if err != nil {
+		return "", fmt.Errorf("StandardHashReader::ReadHash error while hashing file %s: %w", path, err)
+	}
+	return hash, nil
+}
+
+// StatHashReader make hash of local file from its size and modification time without reading it,
+// hash does not depend on path, so file moved within source keeps it
+type StatHashReader struct {
+}
+
+func NewStatHashReader() *StatHashReader {
+	return &StatHashReader{}
+}
+
+func (s *StatHashReader) ReadHash(_ context.Context, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("StatHashReader::ReadHash error while reading stat of file %s: %w", path, err)
+	}
+	return helpers.HashBytes([]byte(fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano()))), nil
+}
+
+// UnknownHashReader return empty hash, used when hash can not be computed (e.g. size and mtime of remote file),
+// so file is always considered changed
+type UnknownHashReader struct {
+}
+
+func NewUnknownHashReader() *UnknownHashReader {
+	return &UnknownHashReader{}
+}
+
+func (u *UnknownHashReader) ReadHash(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+
+// ServerHashReader compute hash on FTP server (HASH, XSHA256 or XCRC command), so file is not downloaded,
+// when server does not support command for algorithm, files are hashed by fallback reader
+type ServerHashReader struct {
+	ftpConnection *ftp.Connection
+	algorithm     string
+	fallback      HashReader
+	unsupported   atomic.Bool
+}
+
+func NewServerHashReader(ftpConnection *ftp.Connection, algorithm string, fallback HashReader) *ServerHashReader {
+	return &ServerHashReader{ftpConnection: ftpConnection, algorithm: algorithm, fallback: fallback}
+}
+
+func (s *ServerHashReader) ReadHash(ctx context.Context, path string) (string, error) {
+	if s.unsupported.Load() {
+		return s.fallback.ReadHash(ctx, path)
+	}
+	hash, err := s.ftpConnection.Hash(ctx, path, s.algorithm)
+	if errors.Is(err, ftp.ErrorHashUnsupported) {
+		s.unsupported.Store(true)
+		return s.fallback.ReadHash(ctx, path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("ServerHashReader::ReadHash error while hashing file %s: %w", path, err)
+	}
+	return hash, nil
 }
 
 type LogHashReader struct {
@@ -0,0 +1,60 @@
+package file_system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatHashReader_ReadHash(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	files := map[string]string{"a.php": "aaaa", "moved/a.php": "aaaa", "b.php": "bbbbb", "c.php": "cccc"}
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(full, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	later := mtime.Add(time.Second)
+	if err := os.Chtimes(filepath.Join(dir, "c.php"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		a     string
+		b     string
+		equal bool
+	}{
+		{name: "Test 1", a: "a.php", b: "moved/a.php", equal: true},
+		{name: "Test 2", a: "a.php", b: "b.php", equal: false},
+		{name: "Test 3", a: "a.php", b: "c.php", equal: false},
+	}
+	reader := NewStatHashReader()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := reader.ReadHash(context.Background(), filepath.Join(dir, tt.a))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := reader.ReadHash(context.Background(), filepath.Join(dir, tt.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := a == b; got != tt.equal {
+				t.Errorf("StatHashReader::ReadHash() equal hash of %s and %s = %v, want %v", tt.a, tt.b, got, tt.equal)
+			}
+		})
+	}
+	if _, err := reader.ReadHash(context.Background(), filepath.Join(dir, "missing.php")); err == nil {
+		t.Errorf("StatHashReader::ReadHash() error = nil, want error")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package file_system
+
+import (
+	"os"
+	"syscall"
+)
+
+// inode return inode number of file, zero when it is not available
+func inode(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package file_system
+
+import (
+	"os"
+)
+
+// inode return inode number of file, zero when it is not available
+func inode(_ os.FileInfo) uint64 {
+	return 0
+}
@@ -7,45 +7,62 @@ import (
 	"os"
 
 	ftp2 "github.com/bednarradek/php-deployer/pkg/ftp"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
 )
 
 type Lister interface {
 	List(ctx context.Context, dir string) ([]FileSystemObject, error)
 }
 
+// RecursiveLister list directory tree level by level, directories of one level are listed concurrently
 type RecursiveLister struct {
-	lister Lister
+	lister  Lister
+	workers int
+}
+
+func NewRecursiveLister(lister Lister, workers int) *RecursiveLister {
+	return &RecursiveLister{lister: lister, workers: workers}
 }
 
-func NewRecursiveLister(lister Lister) *RecursiveLister {
-	return &RecursiveLister{lister: lister}
+type recursiveJob struct {
+	abs string
+	rel string
 }
 
 func (r RecursiveLister) List(ctx context.Context, dir string) ([]FileSystemObject, error) {
-	return r.list(ctx, dir, "")
+	res := make([]FileSystemObject, 0, 100)
+	level := []recursiveJob{{abs: dir, rel: ""}}
+	for len(level) > 0 {
+		lists, err := helpers.RunWorkers(ctx, r.workers, level, func(ctx context.Context, job recursiveJob) ([]FileSystemObject, error) {
+			return r.list(ctx, job)
+		})
+		if err != nil {
+			return nil, err
+		}
+		next := make([]recursiveJob, 0)
+		for _, list := range lists {
+			for _, object := range list {
+				res = append(res, object)
+				if object.IsDir() {
+					next = append(next, recursiveJob{abs: fmt.Sprintf("%s%s", dir, object.GetName()), rel: object.GetName()})
+				}
+			}
+		}
+		level = next
+	}
+	return res, nil
 }
 
-func (r RecursiveLister) list(ctx context.Context, dir string, rel string) ([]FileSystemObject, error) {
-	list, err := r.lister.List(ctx, dir)
+func (r RecursiveLister) list(ctx context.Context, job recursiveJob) ([]FileSystemObject, error) {
+	list, err := r.lister.List(ctx, job.abs)
 	if err != nil {
-		return nil, fmt.Errorf("RecursiveLister::list error while reading directory %s: %w", dir, err)
+		return nil, fmt.Errorf("RecursiveLister::list error while reading directory %s: %w", job.abs, err)
 	}
 	res := make([]FileSystemObject, 0, len(list))
 	for _, file := range list {
-		absPath := fmt.Sprintf("%s/%s", dir, file.GetName())
-		relPath := fmt.Sprintf("%s/%s", rel, file.GetName())
-		if file.IsDir() {
-			res = append(res, NewRecursiveObject(relPath, true))
-			r, err := r.list(ctx, absPath, relPath)
-			if err != nil {
-				return nil, fmt.Errorf("RecursiveLister::list error while reading directory %s: %w", dir, err)
-			}
-			res = append(res, r...)
-			continue
-		}
-		if file.IsRegular() {
-			res = append(res, NewRecursiveObject(relPath, false))
-			continue
+		relPath := fmt.Sprintf("%s/%s", job.rel, file.GetName())
+		if file.IsDir() || file.IsRegular() {
+			res = append(res, NewRecursiveObject(relPath, file.IsDir(), file.GetSize(), file.GetModTime()))
 		}
 	}
 	return res, nil
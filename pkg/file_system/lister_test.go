@@ -0,0 +1,76 @@
+package file_system
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+type testLister map[string][]FileSystemObject
+
+func (t testLister) List(_ context.Context, dir string) ([]FileSystemObject, error) {
+	list, ok := t[dir]
+	if !ok {
+		return nil, fmt.Errorf("missing directory %s", dir)
+	}
+	return list, nil
+}
+
+func TestRecursiveLister_List(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	lister := testLister{
+		"/www": {
+			NewRecursiveObject("app", true, 0, modTime),
+			NewRecursiveObject("index.php", false, 10, modTime),
+		},
+		"/www/app": {
+			NewRecursiveObject("model", true, 0, modTime),
+			NewRecursiveObject("bootstrap.php", false, 20, modTime),
+		},
+		"/www/app/model": {
+			NewRecursiveObject("User.php", false, 30, modTime),
+		},
+	}
+	tests := []struct {
+		name    string
+		dir     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Test 1",
+			dir:  "/www",
+			want: []string{"/app", "/app/bootstrap.php:20", "/app/model", "/app/model/User.php:30", "/index.php:10"},
+		},
+		{
+			name:    "Test 2",
+			dir:     "/missing",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewRecursiveLister(lister, 2).List(context.Background(), tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("List() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := make([]string, 0, len(res))
+			for _, o := range res {
+				if o.IsDir() {
+					got = append(got, o.GetName())
+					continue
+				}
+				if !o.GetModTime().Equal(modTime) {
+					t.Errorf("List() modTime of %s = %v, want %v", o.GetName(), o.GetModTime(), modTime)
+				}
+				got = append(got, fmt.Sprintf("%s:%d", o.GetName(), o.GetSize()))
+			}
+			sort.Strings(got)
+			if !tt.wantErr && fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,20 @@ package file_system
 
 import (
 	"os"
+	"time"
 
 	"github.com/bednarradek/ftp"
+	"github.com/bednarradek/php-deployer/pkg/helpers"
 )
 
 type FileSystemObject interface {
 	IsDir() bool
 	IsRegular() bool
 	GetName() string
+	// GetSize return size in bytes, zero when unknown
+	GetSize() uint64
+	// GetModTime return time of last modification, zero when unknown
+	GetModTime() time.Time
 }
 
 type SystemObject struct {
@@ -28,6 +34,22 @@ func (s SystemObject) GetName() string {
 	return s.Name()
 }
 
+func (s SystemObject) GetSize() uint64 {
+	info, err := s.Info()
+	if err != nil || info.Size() < 0 {
+		return 0
+	}
+	return uint64(info.Size())
+}
+
+func (s SystemObject) GetModTime() time.Time {
+	info, err := s.Info()
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
 type FtpObject struct {
 	ftp.Entry
 }
@@ -44,8 +66,28 @@ func (f FtpObject) GetName() string {
 	return f.Name
 }
 
+// FtpObject::GetSize size is known from MLSD facts or LIST output
+func (f FtpObject) GetSize() uint64 {
+	return f.Size
+}
+
+// FtpObject::GetModTime time is precise only with MLSD, LIST output may omit seconds or year
+func (f FtpObject) GetModTime() time.Time {
+	return f.Time
+}
+
 type LogFile struct {
-	Objects []LogObject `json:"objects"`
+	// Algorithm used for hashes of objects, empty in log files written before algorithm was configurable (sha256)
+	Algorithm string      `json:"algorithm,omitempty"`
+	Objects   []LogObject `json:"objects"`
+}
+
+// LogFile::HashAlgorithm return algorithm of object hashes
+func (l LogFile) HashAlgorithm() string {
+	if l.Algorithm == "" {
+		return helpers.HashSha256
+	}
+	return l.Algorithm
 }
 
 type LogObject struct {
@@ -67,17 +109,29 @@ func (l LogObject) GetName() string {
 	return l.Path
 }
 
+// LogObject::GetSize size is not stored in log file
+func (l LogObject) GetSize() uint64 {
+	return 0
+}
+
+// LogObject::GetModTime time is not stored in log file
+func (l LogObject) GetModTime() time.Time {
+	return time.Time{}
+}
+
 func (l LogObject) GetKey() string {
 	return l.Path
 }
 
 type RecursiveObject struct {
-	path string
-	dir  bool
+	path    string
+	dir     bool
+	size    uint64
+	modTime time.Time
 }
 
-func NewRecursiveObject(path string, dir bool) *RecursiveObject {
-	return &RecursiveObject{path: path, dir: dir}
+func NewRecursiveObject(path string, dir bool, size uint64, modTime time.Time) *RecursiveObject {
+	return &RecursiveObject{path: path, dir: dir, size: size, modTime: modTime}
 }
 
 func (r RecursiveObject) IsDir() bool {
@@ -91,3 +145,11 @@ func (r RecursiveObject) IsRegular() bool {
 func (r RecursiveObject) GetName() string {
 	return r.path
 }
+
+func (r RecursiveObject) GetSize() uint64 {
+	return r.size
+}
+
+func (r RecursiveObject) GetModTime() time.Time {
+	return r.modTime
+}
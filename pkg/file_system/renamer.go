@@ -0,0 +1,42 @@
+package file_system
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bednarradek/php-deployer/pkg/ftp"
+)
+
+type Renamer interface {
+	Rename(ctx context.Context, from string, to string) error
+}
+
+type SystemRenamer struct {
+}
+
+func NewSystemRenamer() *SystemRenamer {
+	return &SystemRenamer{}
+}
+
+func (s SystemRenamer) Rename(_ context.Context, from string, to string) error {
+	if err := os.Rename(from, to); err != nil {
+		return fmt.Errorf("SystemRenamer::Rename error while renaming %s to %s: %w", from, to, err)
+	}
+	return nil
+}
+
+type FtpRenamer struct {
+	ftpConnection *ftp.Connection
+}
+
+func NewFtpRenamer(ftpConnection *ftp.Connection) *FtpRenamer {
+	return &FtpRenamer{ftpConnection: ftpConnection}
+}
+
+func (f FtpRenamer) Rename(ctx context.Context, from string, to string) error {
+	if err := f.ftpConnection.Rename(ctx, from, to); err != nil {
+		return fmt.Errorf("FtpRenamer::Rename error while renaming %s to %s: %w", from, to, err)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/bednarradek/php-deployer/pkg/ftp"
+	"github.com/bednarradek/php-deployer/pkg/throttle"
 )
 
 type Writer interface {
@@ -35,17 +36,19 @@ func (s SystemWriter) Write(_ context.Context, path string, data []byte) error {
 	return nil
 }
 
+// FtpWriter upload files, upload bandwidth is limited by throttle when it is set
 type FtpWriter struct {
 	ftpConnection *ftp.Connection
 	defaultMode   string
+	throttle      *throttle.Throttle
 }
 
-func NewFtpWriter(ftpConnection *ftp.Connection, defaultMode string) *FtpWriter {
-	return &FtpWriter{ftpConnection: ftpConnection, defaultMode: defaultMode}
+func NewFtpWriter(ftpConnection *ftp.Connection, defaultMode string, throttle *throttle.Throttle) *FtpWriter {
+	return &FtpWriter{ftpConnection: ftpConnection, defaultMode: defaultMode, throttle: throttle}
 }
 
 func (f FtpWriter) Write(ctx context.Context, path string, data []byte) error {
-	buff := bytes.NewBuffer(data)
+	buff := f.throttle.Reader(ctx, bytes.NewBuffer(data))
 	if err := f.ftpConnection.Stor(ctx, path, buff); err != nil {
 		return fmt.Errorf("FtpWriter::Write error while writing file %s: %w", path, err)
 	}
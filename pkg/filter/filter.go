@@ -1,25 +1,52 @@
 package filter
 
 import (
+	"fmt"
 	"regexp"
 )
 
 type Filter interface {
-	Contain(path string) bool
+	Contain(path string, isDir bool) bool
 }
 
+// FileSystemFilter match paths against regular expressions compiled once
 type FileSystemFilter struct {
-	ignoreList []string
+	ignoreList []*regexp.Regexp
 }
 
-func NewFileSystemFilter(ignoreList []string) *FileSystemFilter {
-	return &FileSystemFilter{ignoreList: ignoreList}
+func NewFileSystemFilter(ignoreList []string) (*FileSystemFilter, error) {
+	res := make([]*regexp.Regexp, 0, len(ignoreList))
+	for _, ignore := range ignoreList {
+		r, err := regexp.Compile(ignore)
+		if err != nil {
+			return nil, fmt.Errorf("FileSystemFilter::NewFileSystemFilter error while compiling %q: %w", ignore, err)
+		}
+		res = append(res, r)
+	}
+	return &FileSystemFilter{ignoreList: res}, nil
 }
 
-func (f *FileSystemFilter) Contain(path string) bool {
+func (f *FileSystemFilter) Contain(path string, _ bool) bool {
 	for _, ignore := range f.ignoreList {
-		match, _ := regexp.MatchString(ignore, path)
-		if match {
+		if ignore.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChainFilter contain path when any of its filters contains it
+type ChainFilter struct {
+	filters []Filter
+}
+
+func NewChainFilter(filters ...Filter) *ChainFilter {
+	return &ChainFilter{filters: filters}
+}
+
+func (c *ChainFilter) Contain(path string, isDir bool) bool {
+	for _, f := range c.filters {
+		if f.Contain(path, isDir) {
 			return true
 		}
 	}
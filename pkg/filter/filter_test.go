@@ -110,10 +110,11 @@ func TestFileFilter_Contain(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			f := &FileSystemFilter{
-				ignoreList: tt.fields.ignoreList,
+			f, err := NewFileSystemFilter(tt.fields.ignoreList)
+			if err != nil {
+				t.Fatalf("NewFileSystemFilter() error = %v", err)
 			}
-			if got := f.Contain(tt.args.path); got != tt.want {
+			if got := f.Contain(tt.args.path, false); got != tt.want {
 				t.Errorf("Contain() = %v, want %v", got, tt.want)
 			}
 		})
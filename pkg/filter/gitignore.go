@@ -0,0 +1,152 @@
+package filter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ignorePattern struct {
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// GitIgnoreFilter match paths with gitignore semantics: globs, "**", negation with "!" and directory-only patterns,
+// path is contained when it or any of its parent directories is matched by the last matching pattern
+type GitIgnoreFilter struct {
+	patterns []ignorePattern
+}
+
+func NewGitIgnoreFilter(patterns []string) (*GitIgnoreFilter, error) {
+	res := make([]ignorePattern, 0, len(patterns))
+	for _, p := range patterns {
+		pattern, ok, err := compileIgnorePattern(p)
+		if err != nil {
+			return nil, fmt.Errorf("GitIgnoreFilter::NewGitIgnoreFilter error while compiling %q: %w", p, err)
+		}
+		if ok {
+			res = append(res, pattern)
+		}
+	}
+	return &GitIgnoreFilter{patterns: res}, nil
+}
+
+// ParseIgnoreFile return patterns from content of ignore file, empty lines and comments are skipped,
+// escaped "\#" at the beginning of line is kept as pattern
+func ParseIgnoreFile(content []byte) []string {
+	res := make([]string, 0)
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		l := strings.TrimRight(string(line), "\r")
+		if strings.TrimSpace(l) == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		res = append(res, l)
+	}
+	return res
+}
+
+func (g *GitIgnoreFilter) Contain(path string, isDir bool) bool {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return false
+	}
+	// excluded parent directory can not be re-included
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if g.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return g.match(path, isDir)
+}
+
+func (g *GitIgnoreFilter) match(path string, isDir bool) bool {
+	res := false
+	for _, p := range g.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.regex.MatchString(path) {
+			res = !p.negate
+		}
+	}
+	return res
+}
+
+// compileIgnorePattern convert gitignore pattern to regular expression, returns false for empty pattern and comment,
+// leading "\#" and "\!" are literal characters as in git
+func compileIgnorePattern(p string) (ignorePattern, bool, error) {
+	res := ignorePattern{}
+	if strings.HasPrefix(p, "#") {
+		return res, false, nil
+	}
+	// trailing spaces are ignored unless escaped
+	if !strings.HasSuffix(p, "\\ ") {
+		p = strings.TrimRight(p, " ")
+	}
+	if strings.HasPrefix(p, "!") {
+		res.negate = true
+		p = p[1:]
+	}
+	if strings.HasSuffix(p, "/") {
+		res.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+	if p == "" {
+		return res, false, nil
+	}
+	// pattern with slash at the beginning or in the middle is relative to root
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case strings.HasPrefix(p[i:], "**/") && (i == 0 || p[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "/**") && i+3 == len(p):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(p):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		case c == '[':
+			end := strings.IndexByte(p[i+1:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := p[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, "\\", "\\\\") + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	r, err := regexp.Compile(b.String())
+	if err != nil {
+		return res, false, err
+	}
+	res.regex = r
+	return res, true, nil
+}
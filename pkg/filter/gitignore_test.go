@@ -0,0 +1,165 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGitIgnoreFilter_Contain(t *testing.T) {
+	type args struct {
+		path  string
+		isDir bool
+	}
+	tests := []struct {
+		name     string
+		patterns []string
+		args     args
+		want     bool
+	}{
+		{
+			name:     "Test 1",
+			patterns: []string{".DS_Store"},
+			args:     args{path: "/app/.DS_Store"},
+			want:     true,
+		},
+		{
+			name:     "Test 2",
+			patterns: []string{"/vendor"},
+			args:     args{path: "/vendor/autoload.php"},
+			want:     true,
+		},
+		{
+			name:     "Test 3",
+			patterns: []string{"/vendor"},
+			args:     args{path: "/app/vendor"},
+			want:     false,
+		},
+		{
+			name:     "Test 4",
+			patterns: []string{"*.log", "!important.log"},
+			args:     args{path: "/log/important.log"},
+			want:     false,
+		},
+		{
+			name:     "Test 5",
+			patterns: []string{"*.log", "!important.log"},
+			args:     args{path: "/log/error.log"},
+			want:     true,
+		},
+		{
+			name:     "Test 6",
+			patterns: []string{"cache/"},
+			args:     args{path: "/temp/cache"},
+			want:     false,
+		},
+		{
+			name:     "Test 7",
+			patterns: []string{"cache/"},
+			args:     args{path: "/temp/cache", isDir: true},
+			want:     true,
+		},
+		{
+			name:     "Test 8",
+			patterns: []string{"src/**/*.ts"},
+			args:     args{path: "/src/a/b/index.ts"},
+			want:     true,
+		},
+		{
+			name:     "Test 9",
+			patterns: []string{"src/**/*.ts"},
+			args:     args{path: "/src/index.ts"},
+			want:     true,
+		},
+		{
+			name:     "Test 10",
+			patterns: []string{"**/node_modules"},
+			args:     args{path: "/assets/node_modules/x/index.js"},
+			want:     true,
+		},
+		{
+			name:     "Test 11",
+			patterns: []string{"temp/*", "!temp/.gitkeep"},
+			args:     args{path: "/temp/.gitkeep"},
+			want:     false,
+		},
+		{
+			name:     "Test 12",
+			patterns: []string{"temp", "!temp/.gitkeep"},
+			args:     args{path: "/temp/.gitkeep"},
+			want:     true,
+		},
+		{
+			name:     "Test 13",
+			patterns: []string{"file[0-9].txt"},
+			args:     args{path: "/file1.txt"},
+			want:     true,
+		},
+		{
+			name:     "Test 14",
+			patterns: []string{"docs/**"},
+			args:     args{path: "/docs", isDir: true},
+			want:     false,
+		},
+		{
+			name:     "Test 15",
+			patterns: []string{`\#notes.txt`},
+			args:     args{path: "/#notes.txt"},
+			want:     true,
+		},
+		{
+			name:     "Test 16",
+			patterns: []string{"#notes.txt"},
+			args:     args{path: "/#notes.txt"},
+			want:     false,
+		},
+		{
+			name:     "Test 17",
+			patterns: []string{"*.log", `\!important.log`},
+			args:     args{path: "/important.log"},
+			want:     true,
+		},
+		{
+			name:     "Test 18",
+			patterns: []string{`\!important.log`},
+			args:     args{path: "/log/!important.log"},
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGitIgnoreFilter(tt.patterns)
+			if err != nil {
+				t.Fatalf("NewGitIgnoreFilter() error = %v", err)
+			}
+			if got := g.Contain(tt.args.path, tt.args.isDir); got != tt.want {
+				t.Errorf("Contain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "Test 1",
+			content: "# comment\n\n*.log\r\n/vendor\n",
+			want:    []string{"*.log", "/vendor"},
+		},
+		{
+			name:    "Test 2",
+			content: "\\#notes.txt\n\\!important.log\n",
+			want:    []string{`\#notes.txt`, `\!important.log`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseIgnoreFile([]byte(tt.content)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseIgnoreFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,40 +2,96 @@ package ftp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/textproto"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/bednarradek/ftp"
 	"github.com/silenceper/pool"
 )
 
-const ftpPermissionDeniedCode = 550
+const (
+	ftpPermissionDeniedCode = 550
+	// server does not accept more connections (e.g. too many connections from the same IP)
+	ftpServiceNotAvailableCode = 421
+)
+
+const (
+	defaultPoolInitialCap = 5
+	defaultPoolMaxCap     = 30
+	defaultPoolMaxIdle    = 20
+	// number of attempts to get connection refused by server in adaptive mode
+	refusedAttempts = 10
+	refusedBackoff  = time.Second
+)
 
 var ErrorFtpPermissionDenied = fmt.Errorf("permission denied")
 
+// PoolOptions sizes of connection pool, zero values are replaced by defaults
+type PoolOptions struct {
+	InitialCap int
+	MaxCap     int
+	MaxIdle    int
+	// Adaptive lower number of used connections when server refuses new one and raise it back while operations succeed
+	Adaptive bool
+}
+
+// PoolOptions::normalize fill defaults and keep InitialCap <= MaxIdle <= MaxCap
+func (o PoolOptions) normalize() PoolOptions {
+	if o.MaxCap <= 0 {
+		o.MaxCap = defaultPoolMaxCap
+	}
+	if o.MaxIdle <= 0 {
+		o.MaxIdle = defaultPoolMaxIdle
+	}
+	if o.MaxIdle > o.MaxCap {
+		o.MaxIdle = o.MaxCap
+	}
+	if o.InitialCap <= 0 {
+		o.InitialCap = defaultPoolInitialCap
+		// server may refuse some of initial connections before adaptive limit can shrink
+		if o.Adaptive {
+			o.InitialCap = 1
+		}
+	}
+	if o.InitialCap > o.MaxIdle {
+		o.InitialCap = o.MaxIdle
+	}
+	return o
+}
+
 type Connection struct {
 	once sync.Once
 
 	url       string
 	user      string
 	password  string
+	options   PoolOptions
 	ftpConfig *pool.Config
 	ftpPool   pool.Pool
+	// limit of connections used at once, shared by transfer and hash pools
+	limiter *Limiter
+
+	// separate pool of connections for server-side hash commands, created on first use
+	hashMu    sync.Mutex
+	hashConns pool.Pool
 }
 
-func NewConnection(url string, user string, password string) *Connection {
-	return &Connection{url: url, user: user, password: password}
+func NewConnection(url string, user string, password string, options PoolOptions) *Connection {
+	options = options.normalize()
+	return &Connection{url: url, user: user, password: password, options: options, limiter: NewLimiter(options.MaxCap)}
 }
 
 func (f *Connection) Connect() (err error) {
 	f.once.Do(func() {
 		f.ftpConfig = &pool.Config{
-			InitialCap: 5,
-			MaxCap:     30,
-			MaxIdle:    20,
+			InitialCap: f.options.InitialCap,
+			MaxCap:     f.options.MaxCap,
+			MaxIdle:    f.options.MaxIdle,
 			Factory: func() (interface{}, error) {
 				ftpCon, err := ftp.Dial(f.url, ftp.DialWithTimeout(60*time.Second))
 				if err != nil {
@@ -54,6 +110,12 @@ func (f *Connection) Connect() (err error) {
 		}
 		var p pool.Pool
 		p, err = pool.NewChannelPool(f.ftpConfig)
+		if err != nil && f.options.Adaptive && isRefused(err) {
+			// server refused some of initial connections, pool is filled on demand under adaptive limit
+			f.limiter.Shrink()
+			f.ftpConfig.InitialCap = 0
+			p, err = pool.NewChannelPool(f.ftpConfig)
+		}
 		if err != nil {
 			err = fmt.Errorf("Connection::Connect error while creating new channel pool: %w", err)
 			return
@@ -63,20 +125,65 @@ func (f *Connection) Connect() (err error) {
 	return
 }
 
+// Connection::get get connection from pool, number of used connections is limited by limiter,
+// in adaptive mode connection refused by server lowers the limit and is requested again when other connection is returned
+func (f *Connection) get() (interface{}, error) {
+	return f.getFrom(f.ftpPool)
+}
+
+// Connection::getFrom get connection from given pool of connection, see Connection::get
+func (f *Connection) getFrom(p pool.Pool) (interface{}, error) {
+	for attempt := 1; ; attempt++ {
+		f.limiter.Acquire()
+		con, err := p.Get()
+		if err == nil {
+			return con, nil
+		}
+		if !f.options.Adaptive || !isRefused(err) || attempt >= refusedAttempts {
+			f.limiter.Release(false)
+			return nil, err
+		}
+		f.limiter.Shrink()
+		f.limiter.Release(false)
+		time.Sleep(time.Duration(attempt) * refusedBackoff)
+	}
+}
+
+// Connection::put return connection to pool, err is result of operation done with connection
+func (f *Connection) put(con interface{}, err error) {
+	f.putTo(f.ftpPool, con, err)
+}
+
+// Connection::putTo return connection to given pool, only successful operations grow adaptive limit
+func (f *Connection) putTo(p pool.Pool, con interface{}, err error) {
+	_ = p.Put(con)
+	f.limiter.Release(err == nil)
+}
+
+// isRefused check if server refused new connection
+func isRefused(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code == ftpServiceNotAvailableCode {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
 func (f *Connection) Close() {
+	f.CloseHashConnections()
 	if f.ftpPool == nil {
 		return
 	}
 	f.ftpPool.Release()
 }
 
-func (f *Connection) FileSize(_ context.Context, path string) (int64, error) {
-	con, err := f.ftpPool.Get()
+func (f *Connection) FileSize(_ context.Context, path string) (_ int64, err error) {
+	con, err := f.get()
 	if err != nil {
 		return 0, fmt.Errorf("Connection::FileSize error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	res, err := ftpCon.FileSize(path)
@@ -89,13 +196,13 @@ func (f *Connection) FileSize(_ context.Context, path string) (int64, error) {
 	return res, nil
 }
 
-func (f *Connection) Delete(_ context.Context, path string) error {
-	con, err := f.ftpPool.Get()
+func (f *Connection) Delete(_ context.Context, path string) (err error) {
+	con, err := f.get()
 	if err != nil {
 		return fmt.Errorf("Connection::Delete error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	if err := ftpCon.Delete(path); err != nil {
@@ -107,13 +214,33 @@ func (f *Connection) Delete(_ context.Context, path string) error {
 	return nil
 }
 
-func (f *Connection) RemoveDirRecur(_ context.Context, path string) error {
-	con, err := f.ftpPool.Get()
+// Connection::RemoveDir remove empty directory, server refuses to remove directory with content
+func (f *Connection) RemoveDir(_ context.Context, path string) (err error) {
+	con, err := f.get()
+	if err != nil {
+		return fmt.Errorf("Connection::RemoveDir error while getting connection from pool: %w", err)
+	}
+	defer func() {
+		f.put(con, err)
+	}()
+	ftpCon := con.(*ftp.ServerConn)
+	if err := ftpCon.RemoveDir(path); err != nil {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code == ftpPermissionDeniedCode {
+			return ErrorFtpPermissionDenied
+		}
+		return fmt.Errorf("Connection::RemoveDir error while deleting directory %s: %w", path, err)
+	}
+	return nil
+}
+
+func (f *Connection) RemoveDirRecur(_ context.Context, path string) (err error) {
+	con, err := f.get()
 	if err != nil {
 		return fmt.Errorf("Connection::RemoveDirRecur error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	if err := ftpCon.RemoveDirRecur(path); err != nil {
@@ -125,13 +252,14 @@ func (f *Connection) RemoveDirRecur(_ context.Context, path string) error {
 	return nil
 }
 
-func (f *Connection) List(_ context.Context, dir string) ([]*ftp.Entry, error) {
-	con, err := f.ftpPool.Get()
+// Connection::List list directory with MLSD when server supports it (precise size and modify time), LIST otherwise
+func (f *Connection) List(_ context.Context, dir string) (_ []*ftp.Entry, err error) {
+	con, err := f.get()
 	if err != nil {
 		return nil, fmt.Errorf("Connection::List error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	list, err := ftpCon.List(dir)
@@ -144,13 +272,13 @@ func (f *Connection) List(_ context.Context, dir string) ([]*ftp.Entry, error) {
 	return list, nil
 }
 
-func (f *Connection) Walk(_ context.Context, dir string) ([]*ftp.Entry, error) {
-	con, err := f.ftpPool.Get()
+func (f *Connection) Walk(_ context.Context, dir string) (_ []*ftp.Entry, err error) {
+	con, err := f.get()
 	if err != nil {
 		return nil, fmt.Errorf("Connection::Walk error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	res := make([]*ftp.Entry, 0, 500)
@@ -167,13 +295,13 @@ func (f *Connection) Walk(_ context.Context, dir string) ([]*ftp.Entry, error) {
 	return res, nil
 }
 
-func (f *Connection) Retr(_ context.Context, path string) (*ftp.Response, error) {
-	con, err := f.ftpPool.Get()
+func (f *Connection) Retr(_ context.Context, path string) (_ *ftp.Response, err error) {
+	con, err := f.get()
 	if err != nil {
 		return nil, fmt.Errorf("FtpReader::Retr error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	response, err := ftpCon.Retr(path)
@@ -186,13 +314,13 @@ func (f *Connection) Retr(_ context.Context, path string) (*ftp.Response, error)
 	return response, nil
 }
 
-func (f *Connection) Stor(_ context.Context, path string, r io.Reader) error {
-	con, err := f.ftpPool.Get()
+func (f *Connection) Stor(_ context.Context, path string, r io.Reader) (err error) {
+	con, err := f.get()
 	if err != nil {
 		return fmt.Errorf("FtpReader::Stor error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	if err := ftpCon.Stor(path, r); err != nil {
@@ -204,13 +332,13 @@ func (f *Connection) Stor(_ context.Context, path string, r io.Reader) error {
 	return nil
 }
 
-func (f *Connection) MakeDir(_ context.Context, path string) error {
-	con, err := f.ftpPool.Get()
+func (f *Connection) MakeDir(_ context.Context, path string) (err error) {
+	con, err := f.get()
 	if err != nil {
 		return fmt.Errorf("FtpReader::MakeDir error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	if err := ftpCon.MakeDir(path); err != nil {
@@ -222,13 +350,13 @@ func (f *Connection) MakeDir(_ context.Context, path string) error {
 	return nil
 }
 
-func (f *Connection) Chmod(_ context.Context, path string, mode string) error {
-	con, err := f.ftpPool.Get()
+func (f *Connection) Chmod(_ context.Context, path string, mode string) (err error) {
+	con, err := f.get()
 	if err != nil {
 		return fmt.Errorf("FtpReader::Chmod error while getting connection from pool: %w", err)
 	}
 	defer func() {
-		_ = f.ftpPool.Put(con)
+		f.put(con, err)
 	}()
 	ftpCon := con.(*ftp.ServerConn)
 	if err := ftpCon.Chmod(path, mode); err != nil {
@@ -239,3 +367,22 @@ func (f *Connection) Chmod(_ context.Context, path string, mode string) error {
 	}
 	return nil
 }
+
+func (f *Connection) Rename(_ context.Context, from string, to string) (err error) {
+	con, err := f.get()
+	if err != nil {
+		return fmt.Errorf("Connection::Rename error while getting connection from pool: %w", err)
+	}
+	defer func() {
+		f.put(con, err)
+	}()
+	ftpCon := con.(*ftp.ServerConn)
+	if err := ftpCon.Rename(from, to); err != nil {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code == ftpPermissionDeniedCode {
+			return ErrorFtpPermissionDenied
+		}
+		return fmt.Errorf("Connection::Rename error while renaming %s to %s: %w", from, to, err)
+	}
+	return nil
+}
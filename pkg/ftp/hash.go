@@ -0,0 +1,197 @@
+package ftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/silenceper/pool"
+)
+
+var ErrorHashUnsupported = errors.New("hash command is not supported by server")
+
+const (
+	HashCommandHash    = "HASH"
+	HashCommandXsha256 = "XSHA256"
+	HashCommandXcrc    = "XCRC"
+)
+
+// hashConnection control connection used only for server-side hash commands, which are not supported by FTP client
+type hashConnection struct {
+	conn     *textproto.Conn
+	features map[string]string
+}
+
+func dialHashConnection(url string, user string, password string) (*hashConnection, error) {
+	netConn, err := net.DialTimeout("tcp", url, 60*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("hashConnection::dial error while connecting to FTP server: %w", err)
+	}
+	conn := textproto.NewConn(netConn)
+	h := &hashConnection{conn: conn, features: make(map[string]string)}
+	if _, _, err := conn.ReadResponse(220); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("hashConnection::dial error while reading greeting: %w", err)
+	}
+	code, _, err := h.cmd(-1, "USER %s", user)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("hashConnection::dial error while logging to FTP server: %w", err)
+	}
+	if code == 331 {
+		if _, _, err := h.cmd(230, "PASS %s", password); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("hashConnection::dial error while logging to FTP server: %w", err)
+		}
+	}
+	if _, message, err := h.cmd(211, "FEAT"); err == nil {
+		for _, line := range strings.Split(message, "\n")[1:] {
+			name, params, _ := strings.Cut(strings.TrimSpace(line), " ")
+			if name != "" && name != "End" {
+				h.features[strings.ToUpper(name)] = params
+			}
+		}
+	}
+	return h, nil
+}
+
+func (h *hashConnection) cmd(expected int, format string, args ...any) (int, string, error) {
+	id, err := h.conn.Cmd(format, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	h.conn.StartResponse(id)
+	defer h.conn.EndResponse(id)
+	return h.conn.ReadResponse(expected)
+}
+
+// hashConnection::command return first supported command computing hash by algorithm (sha256 or crc32)
+func (h *hashConnection) command(algorithm string) string {
+	switch algorithm {
+	case "sha256":
+		if params, ok := h.features[HashCommandHash]; ok && strings.Contains(strings.ToUpper(params), "SHA-256") {
+			return HashCommandHash
+		}
+		if _, ok := h.features[HashCommandXsha256]; ok {
+			return HashCommandXsha256
+		}
+	case "crc32":
+		if params, ok := h.features[HashCommandHash]; ok && strings.Contains(strings.ToUpper(params), "CRC32") {
+			return HashCommandHash
+		}
+		if _, ok := h.features[HashCommandXcrc]; ok {
+			return HashCommandXcrc
+		}
+	}
+	return ""
+}
+
+// hashConnection::hash compute hash on server, result is lowercase hex
+func (h *hashConnection) hash(path string, algorithm string) (string, error) {
+	command := h.command(algorithm)
+	if command == "" {
+		return "", fmt.Errorf("hashConnection::hash error while hashing %s: %w", path, ErrorHashUnsupported)
+	}
+	if command == HashCommandHash {
+		name := "SHA-256"
+		if algorithm == "crc32" {
+			name = "CRC32"
+		}
+		if _, _, err := h.cmd(200, "OPTS HASH %s", name); err != nil {
+			return "", fmt.Errorf("hashConnection::hash error while selecting hash algorithm %s: %w", name, err)
+		}
+	}
+	code, message, err := h.cmd(-1, "%s %s", command, path)
+	if err != nil {
+		return "", fmt.Errorf("hashConnection::hash error while hashing %s: %w", path, err)
+	}
+	if code/100 != 2 {
+		return "", fmt.Errorf("hashConnection::hash error while hashing %s: %d %s", path, code, message)
+	}
+	return parseHashResponse(command, message, algorithm)
+}
+
+// parseHashResponse read hash from response of HASH ("SHA-256 0-49 <hash> <path>"), XSHA256 and XCRC ("<hash>")
+func parseHashResponse(command string, message string, algorithm string) (string, error) {
+	fields := strings.Fields(message)
+	index := 0
+	if command == HashCommandHash {
+		index = 2
+	}
+	if len(fields) <= index {
+		return "", fmt.Errorf("parseHashResponse invalid response of %s: %q", command, message)
+	}
+	hash := strings.ToLower(fields[index])
+	if algorithm == "crc32" {
+		hash = fmt.Sprintf("%08s", hash)
+	}
+	return hash, nil
+}
+
+// Connection::Hash compute hash of file on server with HASH, XSHA256 or XCRC command,
+// ErrorHashUnsupported is returned when server does not advertise command for algorithm
+func (f *Connection) Hash(_ context.Context, path string, algorithm string) (_ string, err error) {
+	hashPool, err := f.hashPool()
+	if err != nil {
+		return "", fmt.Errorf("Connection::Hash error while creating hash connection pool: %w", err)
+	}
+	con, err := f.getFrom(hashPool)
+	if err != nil {
+		return "", fmt.Errorf("Connection::Hash error while getting connection from pool: %w", err)
+	}
+	defer func() {
+		f.putTo(hashPool, con, err)
+	}()
+	res, err := con.(*hashConnection).hash(path, algorithm)
+	if err != nil {
+		return "", fmt.Errorf("Connection::Hash error while hashing file %s: %w", path, err)
+	}
+	return res, nil
+}
+
+func (h *hashConnection) close() error {
+	_, _, _ = h.cmd(-1, "QUIT")
+	return h.conn.Close()
+}
+
+// Connection::hashPool create pool of hash connections on first use, so nothing is dialed
+// when no remote file has to be hashed, connections are counted by the same limiter as transfer ones
+func (f *Connection) hashPool() (pool.Pool, error) {
+	f.hashMu.Lock()
+	defer f.hashMu.Unlock()
+	if f.hashConns != nil {
+		return f.hashConns, nil
+	}
+	hashConns, err := pool.NewChannelPool(&pool.Config{
+		InitialCap: 0,
+		MaxCap:     f.options.MaxCap,
+		MaxIdle:    f.options.MaxIdle,
+		Factory: func() (interface{}, error) {
+			return dialHashConnection(f.url, f.user, f.password)
+		},
+		Close: func(i interface{}) error {
+			return i.(*hashConnection).close()
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Connection::hashPool error while creating new channel pool: %w", err)
+	}
+	f.hashConns = hashConns
+	return f.hashConns, nil
+}
+
+// Connection::CloseHashConnections close idle hash connections, so they do not hold connections of server
+// while files are transferred, pool is created again by next hash
+func (f *Connection) CloseHashConnections() {
+	f.hashMu.Lock()
+	defer f.hashMu.Unlock()
+	if f.hashConns == nil {
+		return
+	}
+	f.hashConns.Release()
+	f.hashConns = nil
+}
@@ -0,0 +1,165 @@
+package ftp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_parseHashResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		message   string
+		algorithm string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "Test 1",
+			command:   HashCommandHash,
+			message:   "SHA-256 0-4 2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824 /www/index.php",
+			algorithm: "sha256",
+			want:      "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		{
+			name:      "Test 2",
+			command:   HashCommandXcrc,
+			message:   "10A686",
+			algorithm: "crc32",
+			want:      "0010a686",
+		},
+		{
+			name:      "Test 3",
+			command:   HashCommandHash,
+			message:   "SHA-256",
+			algorithm: "sha256",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHashResponse(tt.command, tt.message, tt.algorithm)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseHashResponse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseHashResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// serveHash accept connections of fake FTP server advertising features and answering hash commands with hash
+func serveHash(t *testing.T, features []string, hash string) (string, *atomic.Int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	var dialed atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			dialed.Add(1)
+			go func(conn net.Conn) {
+				defer conn.Close()
+				c := textproto.NewConn(conn)
+				_ = c.PrintfLine("220 ready")
+				for {
+					line, err := c.ReadLine()
+					if err != nil {
+						return
+					}
+					command, _, _ := strings.Cut(line, " ")
+					switch command {
+					case "USER":
+						_ = c.PrintfLine("331 password required")
+					case "PASS":
+						_ = c.PrintfLine("230 logged in")
+					case "FEAT":
+						_ = c.PrintfLine("211-Features:")
+						for _, f := range features {
+							_ = c.PrintfLine(" %s", f)
+						}
+						_ = c.PrintfLine("211 End")
+					case HashCommandXsha256:
+						_ = c.PrintfLine("250 %s", hash)
+					case "QUIT":
+						_ = c.PrintfLine("221 bye")
+						return
+					default:
+						_ = c.PrintfLine("502 not implemented")
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String(), &dialed
+}
+
+func TestConnection_Hash(t *testing.T) {
+	hash := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	tests := []struct {
+		name     string
+		features []string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "Test 1",
+			features: []string{"UTF8", "XSHA256"},
+			want:     hash,
+		},
+		{
+			name:     "Test 2",
+			features: []string{"UTF8"},
+			wantErr:  ErrorHashUnsupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, dialed := serveHash(t, tt.features, strings.ToUpper(hash))
+			f := NewConnection(url, "user", "password", PoolOptions{MaxCap: 2})
+			defer f.Close()
+			// hash connection is dialed only when file is hashed
+			if got := dialed.Load(); got != 0 {
+				t.Fatalf("dialed connections = %v, want %v", got, 0)
+			}
+			for i := 0; i < 3; i++ {
+				got, err := f.Hash(context.Background(), "/www/index.php", "sha256")
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Connection::Hash() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("Connection::Hash() = %v, want %v", got, tt.want)
+				}
+			}
+			// connection is reused from pool
+			if got := dialed.Load(); got != 1 {
+				t.Errorf("dialed connections = %v, want %v", got, 1)
+			}
+			// hash connections are counted by limiter of transfer connections
+			if got := f.limiter.inUse; got != 0 {
+				t.Errorf("used connections = %v, want %v", got, 0)
+			}
+			f.CloseHashConnections()
+			if _, err := f.Hash(context.Background(), "/www/index.php", "sha256"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Connection::Hash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := dialed.Load(); got != 2 {
+				t.Errorf("dialed connections = %v, want %v", got, 2)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package ftp
+
+import (
+	"sync"
+)
+
+// number of successful operations after which adaptive limit grows by one connection
+const limiterGrowAfter = 20
+
+// Limiter limit number of FTP connections used at once, limit shrinks when server refuses new connection
+// and grows back to maximum while operations succeed
+type Limiter struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     int
+	max       int
+	inUse     int
+	successes int
+}
+
+func NewLimiter(max int) *Limiter {
+	if max < 1 {
+		max = 1
+	}
+	l := &Limiter{limit: max, max: max}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// Limiter::Acquire block until number of used connections is lower than current limit
+func (l *Limiter) Acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inUse >= l.limit {
+		l.cond.Wait()
+	}
+	l.inUse++
+}
+
+// Limiter::Release return acquired connection, successful operations grow the limit back
+func (l *Limiter) Release(success bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inUse--
+	if success {
+		l.successes++
+		if l.successes >= limiterGrowAfter && l.limit < l.max {
+			l.limit++
+			l.successes = 0
+		}
+	}
+	l.cond.Broadcast()
+}
+
+// Limiter::Shrink lower limit below number of used connections after server refused acquired one,
+// return new limit
+func (l *Limiter) Shrink() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = l.inUse - 1
+	if l.limit < 1 {
+		l.limit = 1
+	}
+	l.successes = 0
+	return l.limit
+}
+
+// Limiter::Limit return current limit
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
@@ -0,0 +1,187 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/silenceper/pool"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(4)
+	for i := 0; i < 4; i++ {
+		l.Acquire()
+	}
+	// server refused fourth connection
+	if got := l.Shrink(); got != 3 {
+		t.Errorf("Limiter::Shrink() = %v, want %v", got, 3)
+	}
+	l.Release(false)
+	for i := 0; i < 3; i++ {
+		l.Release(true)
+	}
+	for i := 0; i < limiterGrowAfter-3; i++ {
+		l.Acquire()
+		l.Release(true)
+	}
+	if got := l.Limit(); got != 4 {
+		t.Errorf("Limiter::Limit() = %v, want %v", got, 4)
+	}
+	for i := 0; i < limiterGrowAfter; i++ {
+		l.Acquire()
+		l.Release(true)
+	}
+	if got := l.Limit(); got != 4 {
+		t.Errorf("Limiter::Limit() = %v, want %v", got, 4)
+	}
+	l.Acquire()
+	if got := l.Shrink(); got != 1 {
+		t.Errorf("Limiter::Shrink() = %v, want %v", got, 1)
+	}
+}
+
+func TestPoolOptions_normalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		options PoolOptions
+		want    PoolOptions
+	}{
+		{
+			name:    "Test 1",
+			options: PoolOptions{},
+			want:    PoolOptions{InitialCap: 5, MaxCap: 30, MaxIdle: 20},
+		},
+		{
+			name:    "Test 2",
+			options: PoolOptions{MaxCap: 4, Adaptive: true},
+			want:    PoolOptions{InitialCap: 1, MaxCap: 4, MaxIdle: 4, Adaptive: true},
+		},
+		{
+			name:    "Test 3",
+			options: PoolOptions{InitialCap: 1, MaxCap: 8, MaxIdle: 6},
+			want:    PoolOptions{InitialCap: 1, MaxCap: 8, MaxIdle: 6},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.normalize(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PoolOptions::normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isRefused(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Test 1",
+			err:  fmt.Errorf("dial: %w", &textproto.Error{Code: 421, Msg: "Too many connections"}),
+			want: true,
+		},
+		{
+			name: "Test 2",
+			err:  fmt.Errorf("dial: %w", syscall.ECONNREFUSED),
+			want: true,
+		},
+		{
+			name: "Test 3",
+			err:  fmt.Errorf("login: %w", &textproto.Error{Code: 530, Msg: "Login incorrect"}),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRefused(tt.err); got != tt.want {
+				t.Errorf("isRefused() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnection_get(t *testing.T) {
+	// server accepts only two connections
+	var opened atomic.Int32
+	p, err := pool.NewChannelPool(&pool.Config{
+		InitialCap: 0,
+		MaxCap:     3,
+		MaxIdle:    3,
+		Factory: func() (interface{}, error) {
+			if opened.Load() >= 2 {
+				return nil, fmt.Errorf("dial: %w", &textproto.Error{Code: 421, Msg: "Too many connections"})
+			}
+			return opened.Add(1), nil
+		},
+		Close: func(i interface{}) error {
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &Connection{ftpPool: p, limiter: NewLimiter(3), options: PoolOptions{Adaptive: true}}
+
+	first, err := f.get()
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if _, err := f.get(); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		con, err := f.get()
+		if err == nil {
+			f.put(con, nil)
+		}
+		done <- err
+	}()
+	// third connection is refused, limit shrinks and call waits for returned connection
+	time.Sleep(100 * time.Millisecond)
+	f.put(first, nil)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("get() did not return")
+	}
+	if got := f.limiter.Limit(); got != 2 {
+		t.Errorf("Limiter::Limit() = %v, want %v", got, 2)
+	}
+	if got := opened.Load(); got != 2 {
+		t.Errorf("opened connections = %v, want %v", got, 2)
+	}
+
+	// failed operations do not grow the limit back
+	for i := 0; i < limiterGrowAfter; i++ {
+		con, err := f.get()
+		if err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+		f.put(con, errors.New("550 failed"))
+	}
+	if got := f.limiter.Limit(); got != 2 {
+		t.Errorf("Limiter::Limit() = %v, want %v", got, 2)
+	}
+	for i := 0; i < limiterGrowAfter; i++ {
+		con, err := f.get()
+		if err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+		f.put(con, nil)
+	}
+	if got := f.limiter.Limit(); got != 3 {
+		t.Errorf("Limiter::Limit() = %v, want %v", got, 3)
+	}
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseDotEnv parse content of .env file, supports comments, "export" prefix and quoted values
+func ParseDotEnv(content []byte) (map[string]string, error) {
+	res := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("ParseDotEnv missing '=' on line %d", line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("ParseDotEnv invalid quoted value on line %d: %w", line, err)
+			}
+			value = unquoted
+		case strings.HasPrefix(value, `'`) && strings.HasSuffix(value, `'`) && len(value) > 1:
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		res[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ParseDotEnv error while reading content: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "Test 1",
+			content: "# comment\nAPP_ENV=production\n\nexport DB_HOST=localhost # inline comment\n",
+			want:    map[string]string{"APP_ENV": "production", "DB_HOST": "localhost"},
+		},
+		{
+			name:    "Test 2",
+			content: "PASSWORD=\"a#b\\nc\"\nTOKEN='x y'",
+			want:    map[string]string{"PASSWORD": "a#b\nc", "TOKEN": "x y"},
+		},
+		{
+			name:    "Test 3",
+			content: "INVALID",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotEnv([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDotEnv() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDotEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
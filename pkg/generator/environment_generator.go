@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"html/template"
 	"os"
 	"strings"
+	"text/template"
 )
 
 type Generator interface {
@@ -14,26 +14,96 @@ type Generator interface {
 }
 
 type EnvironmentGenerator struct {
-	envs map[string]string
+	envs   map[string]any
+	funcs  template.FuncMap
+	escape string
 }
 
 func NewEnvironmentGenerator() *EnvironmentGenerator {
-	res := make(map[string]string)
+	res := make(map[string]any)
 	for _, v := range os.Environ() {
 		pair := strings.SplitN(v, "=", 2)
 		res[pair[0]] = pair[1]
 	}
 
 	return &EnvironmentGenerator{
-		envs: res,
+		envs:   res,
+		funcs:  make(template.FuncMap),
+		escape: EscapeRaw,
 	}
 }
 
-func (e *EnvironmentGenerator) Generate(_ context.Context, t []byte) ([]byte, error) {
-	tmpl, err := template.New("template").Parse(string(t))
+// EnvironmentGenerator::SetVariable add variable available in templates, overrides environment variable with the same name
+func (e *EnvironmentGenerator) SetVariable(name string, value any) {
+	e.envs[name] = value
+}
+
+// EnvironmentGenerator::SetDefault add variable available in templates only when variable with the same name does not exist
+func (e *EnvironmentGenerator) SetDefault(name string, value any) {
+	if _, ok := e.envs[name]; ok {
+		return
+	}
+	e.envs[name] = value
+}
+
+// EnvironmentGenerator::AddFunction add function available in templates
+func (e *EnvironmentGenerator) AddFunction(name string, function any) {
+	e.funcs[name] = function
+}
+
+// EnvironmentGenerator::WithEscape return generator sharing variables which escapes every printed value with given mode
+func (e *EnvironmentGenerator) WithEscape(mode string) (*EnvironmentGenerator, error) {
+	if mode == "" {
+		mode = EscapeRaw
+	}
+	if _, ok := escapers[mode]; !ok && mode != EscapeRaw {
+		return nil, fmt.Errorf("EnvironmentGenerator::WithEscape unknown escape mode: %s", mode)
+	}
+	return &EnvironmentGenerator{envs: e.envs, funcs: e.funcs, escape: mode}, nil
+}
+
+// EnvironmentGenerator::Parse check template syntax without executing it
+func (e *EnvironmentGenerator) Parse(t []byte) error {
+	if _, err := e.parse(t); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (e *EnvironmentGenerator) parse(t []byte) (*template.Template, error) {
+	funcs := functions()
+	funcs["lookup"] = func(path string) any {
+		if value, ok := e.lookup(path); ok {
+			return value
+		}
+		return ""
+	}
+	for name, function := range e.funcs {
+		funcs[name] = function
+	}
+	for mode, escaper := range escapers {
+		escaper := escaper
+		funcs[escaperName(mode)] = func(value any) string {
+			return escaper(fmt.Sprint(value))
+		}
+	}
+	tmpl, err := template.New("template").Funcs(funcs).Option("missingkey=error").Parse(string(t))
 	if err != nil {
 		return nil, fmt.Errorf("EnvironmentFileGenerator::Generate error while parsing template %w", err)
 	}
+	if e.escape != EscapeRaw {
+		for _, tpl := range tmpl.Templates() {
+			escapeTree(tpl.Tree.Root, escaperName(e.escape))
+		}
+	}
+	return tmpl, nil
+}
+
+func (e *EnvironmentGenerator) Generate(_ context.Context, t []byte) ([]byte, error) {
+	tmpl, err := e.parse(t)
+	if err != nil {
+		return nil, err
+	}
 	res := new(bytes.Buffer)
 	if err := tmpl.Execute(res, e.envs); err != nil {
 		return nil, fmt.Errorf("EnvironmentFileGenerator::Generate error while executing template %w", err)
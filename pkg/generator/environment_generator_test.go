@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestEnvironmentGenerator_Generate(t *testing.T) {
+	type fields struct {
+		envs   map[string]any
+		escape string
+	}
+	type args struct {
+		template string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Test 1",
+			fields: fields{
+				envs:   map[string]any{"PASSWORD": "a&b<c"},
+				escape: EscapeRaw,
+			},
+			args: args{
+				template: "PASSWORD={{.PASSWORD}}",
+			},
+			want: "PASSWORD=a&b<c",
+		},
+		{
+			name: "Test 2",
+			fields: fields{
+				envs:   map[string]any{},
+				escape: EscapeRaw,
+			},
+			args: args{
+				template: "PASSWORD={{.PASSWORD}}",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test 3",
+			fields: fields{
+				envs:   map[string]any{"NAME": ""},
+				escape: EscapeRaw,
+			},
+			args: args{
+				template: `{{.NAME | default "app" | upper}}`,
+			},
+			want: "APP",
+		},
+		{
+			name: "Test 4",
+			fields: fields{
+				envs:   map[string]any{"NAME": ""},
+				escape: EscapeRaw,
+			},
+			args: args{
+				template: `{{required "NAME is required" .NAME}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test 5",
+			fields: fields{
+				envs:   map[string]any{"PASSWORD": `it's \ secret`},
+				escape: EscapePhp,
+			},
+			args: args{
+				template: "'password' => '{{.PASSWORD}}',",
+			},
+			want: `'password' => 'it\'s \\ secret',`,
+		},
+		{
+			name: "Test 6",
+			fields: fields{
+				envs:   map[string]any{"PASSWORD": `a"b`},
+				escape: EscapeJson,
+			},
+			args: args{
+				template: `{"password": "{{.PASSWORD}}"}`,
+			},
+			want: `{"password": "a\"b"}`,
+		},
+		{
+			name: "Test 7",
+			fields: fields{
+				envs:   map[string]any{"PASSWORD": "it's"},
+				escape: EscapeShell,
+			},
+			args: args{
+				template: "PASSWORD={{.PASSWORD}}",
+			},
+			want: `PASSWORD='it'\''s'`,
+		},
+		{
+			name: "Test 8",
+			fields: fields{
+				envs:   map[string]any{"ITEMS": []string{"<a>", "b"}},
+				escape: EscapeHtml,
+			},
+			args: args{
+				template: "{{range .ITEMS}}{{.}};{{end}}",
+			},
+			want: "&lt;a&gt;;b;",
+		},
+		{
+			name: "Test 9",
+			fields: fields{
+				envs:   map[string]any{"TOKEN": "secret"},
+				escape: EscapeRaw,
+			},
+			args: args{
+				template: "{{.TOKEN | b64enc}} {{.TOKEN | b64enc | b64dec | quote}}",
+			},
+			want: `c2VjcmV0 "secret"`,
+		},
+		{
+			name: "Test 10",
+			fields: fields{
+				envs:   map[string]any{},
+				escape: EscapeRaw,
+			},
+			args: args{
+				template: `{{lookup "NAME" | default "app"}}`,
+			},
+			want: "app",
+		},
+		{
+			name: "Test 11",
+			fields: fields{
+				envs:   map[string]any{"secrets": map[string]string{"TOKEN": "secret"}},
+				escape: EscapeShell,
+			},
+			args: args{
+				template: `{{lookup "secrets.TOKEN" | default "none"}} {{lookup "secrets.MISSING" | default "none"}}`,
+			},
+			want: `'secret' 'none'`,
+		},
+		{
+			name: "Test 12",
+			fields: fields{
+				envs:   map[string]any{},
+				escape: EscapeRaw,
+			},
+			args: args{
+				template: `{{required "NAME is required" (lookup "NAME")}}`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &EnvironmentGenerator{
+				envs:   tt.fields.envs,
+				escape: tt.fields.escape,
+			}
+			got, err := e.Generate(context.Background(), []byte(tt.args.template))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Generate() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvironmentGenerator_References(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "Test 1",
+			template: "{{.DB_HOST}}:{{.deploy.commit | upper}} {{if .DEBUG}}{{.DEBUG}}{{end}}",
+			want:     []string{"DB_HOST", "DEBUG", "deploy.commit"},
+		},
+		{
+			name:     "Test 2",
+			template: "{{range .items}}{{.name}}{{$.PREFIX}}{{end}}",
+			want:     []string{"PREFIX", "items"},
+		},
+		{
+			name:     "Test 3",
+			template: "{{.A",
+			wantErr:  true,
+		},
+		{
+			name:     "Test 4",
+			template: `{{lookup "OPTIONAL" | default "value"}}{{.REQUIRED}}`,
+			want:     []string{"REQUIRED"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEnvironmentGenerator().References([]byte(tt.template))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("References() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("References() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
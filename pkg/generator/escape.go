@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"text/template/parse"
+)
+
+const (
+	EscapeRaw   = "raw"
+	EscapePhp   = "php"
+	EscapeJson  = "json"
+	EscapeShell = "shell"
+	EscapeHtml  = "html"
+)
+
+var escapers = map[string]func(string) string{
+	EscapePhp:   escapePhp,
+	EscapeJson:  escapeJson,
+	EscapeShell: escapeShell,
+	EscapeHtml:  template.HTMLEscapeString,
+}
+
+func escaperName(mode string) string {
+	return fmt.Sprintf("_escape_%s", mode)
+}
+
+// escapePhp escape value for PHP single-quoted string
+func escapePhp(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// escapeJson escape value for JSON string (without surrounding quotes)
+func escapeJson(value string) string {
+	res, _ := json.Marshal(value)
+	return string(res[1 : len(res)-1])
+}
+
+// escapeShell quote value as a single shell word
+func escapeShell(value string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", `'\''`))
+}
+
+// escapeTree append escaper to every action printing a value
+func escapeTree(node parse.Node, name string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeTree(child, name)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(name).SetTree(nil).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		escapeTree(n.List, name)
+		escapeTree(n.ElseList, name)
+	case *parse.RangeNode:
+		escapeTree(n.List, name)
+		escapeTree(n.ElseList, name)
+	case *parse.WithNode:
+		escapeTree(n.List, name)
+		escapeTree(n.ElseList, name)
+	}
+}
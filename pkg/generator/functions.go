@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// functions available in all templates
+func functions() template.FuncMap {
+	return template.FuncMap{
+		"default":  defaultValue,
+		"required": required,
+		"quote":    quote,
+		"b64enc":   b64enc,
+		"b64dec":   b64dec,
+		"json":     toJson,
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"env":      os.Getenv,
+		"file":     readFile,
+	}
+}
+
+// defaultValue return def when value is empty, usage {{ .VAR | default "value" }}
+func defaultValue(def any, value any) any {
+	if isEmpty(value) {
+		return def
+	}
+	return value
+}
+
+// required fail template execution with message when value is empty, usage {{ required "VAR is required" .VAR }}
+func required(message string, value any) (any, error) {
+	if isEmpty(value) {
+		return nil, fmt.Errorf("%s", message)
+	}
+	return value, nil
+}
+
+func quote(value any) string {
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+func b64enc(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+func b64dec(value string) (string, error) {
+	res, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("b64dec error while decoding value: %w", err)
+	}
+	return string(res), nil
+}
+
+func toJson(value any) (string, error) {
+	res, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("json error while marshalling value: %w", err)
+	}
+	return string(res), nil
+}
+
+func readFile(path string) (string, error) {
+	res, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("file error while reading file %s: %w", path, err)
+	}
+	return string(res), nil
+}
+
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Map, reflect.Slice, reflect.Array:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
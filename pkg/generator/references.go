@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// EnvironmentGenerator::References return sorted dotted paths of all variables referenced by template (e.g. "deploy.commit"),
+// fields relative to changed dot inside range and with blocks are skipped
+func (e *EnvironmentGenerator) References(t []byte) ([]string, error) {
+	tmpl, err := e.parse(t)
+	if err != nil {
+		return nil, err
+	}
+	references := make(map[string]bool)
+	for _, tpl := range tmpl.Templates() {
+		if tpl.Tree != nil {
+			collectReferences(tpl.Tree.Root, true, references)
+		}
+	}
+	res := make([]string, 0, len(references))
+	for reference := range references {
+		res = append(res, reference)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func collectReferences(node parse.Node, rootDot bool, references map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectReferences(child, rootDot, references)
+		}
+	case *parse.ActionNode:
+		collectReferences(n.Pipe, rootDot, references)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectReferences(cmd, rootDot, references)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectReferences(arg, rootDot, references)
+		}
+	case *parse.FieldNode:
+		if rootDot {
+			references[strings.Join(n.Ident, ".")] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			references[strings.Join(n.Ident[1:], ".")] = true
+		}
+	case *parse.ChainNode:
+		collectReferences(n.Node, rootDot, references)
+	case *parse.IfNode:
+		collectReferences(n.Pipe, rootDot, references)
+		collectReferences(n.List, rootDot, references)
+		collectReferences(n.ElseList, rootDot, references)
+	case *parse.RangeNode:
+		collectReferences(n.Pipe, rootDot, references)
+		collectReferences(n.List, false, references)
+		collectReferences(n.ElseList, rootDot, references)
+	case *parse.WithNode:
+		collectReferences(n.Pipe, rootDot, references)
+		collectReferences(n.List, false, references)
+		collectReferences(n.ElseList, rootDot, references)
+	case *parse.TemplateNode:
+		collectReferences(n.Pipe, rootDot, references)
+	}
+}
+
+// EnvironmentGenerator::HasVariable check whether variable on dotted path exists
+func (e *EnvironmentGenerator) HasVariable(path string) bool {
+	_, ok := e.lookup(path)
+	return ok
+}
+
+// EnvironmentGenerator::lookup return variable on dotted path, template function {{ lookup "VAR" | default "value" }}
+// returns empty string for missing variable so it does not fail template execution as {{ .VAR }} does
+func (e *EnvironmentGenerator) lookup(path string) (any, bool) {
+	var current any = e.envs
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case map[string]string:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,33 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+type Info struct {
+	Commit string
+	Branch string
+	Tag    string
+}
+
+// ReadInfo read commit, branch and tag of git repository in dir, values are empty when they can not be read
+func ReadInfo(ctx context.Context, dir string) Info {
+	return Info{
+		Commit: run(ctx, dir, "rev-parse", "HEAD"),
+		Branch: run(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD"),
+		Tag:    run(ctx, dir, "describe", "--tags", "--exact-match"),
+	}
+}
+
+func run(ctx context.Context, dir string, args ...string) string {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	stdout := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(stdout.String())
+}
@@ -3,9 +3,52 @@ package helpers
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const (
+	HashSha256 = "sha256"
+	// HashXxhash64 fast non-cryptographic 64-bit hash of content
+	HashXxhash64 = "xxhash64"
+	// HashCrc32 32-bit checksum (IEEE, same as FTP XCRC), collides in large trees, meant only for servers hashing by XCRC
+	HashCrc32 = "crc32"
+	// HashSizeMtime no content is read, hash is made from size and modification time of file
+	HashSizeMtime = "size_mtime"
 )
 
 func HashBytes(input []byte) string {
 	sum := sha256.Sum256(input)
 	return hex.EncodeToString(sum[:])
 }
+
+// HashBytesWith hash content with content based algorithm
+func HashBytesWith(algorithm string, input []byte) (string, error) {
+	switch algorithm {
+	case HashSha256:
+		return HashBytes(input), nil
+	case HashXxhash64:
+		return fmt.Sprintf("%016x", xxhash.Sum64(input)), nil
+	case HashCrc32:
+		return fmt.Sprintf("%08x", crc32.ChecksumIEEE(input)), nil
+	default:
+		return "", fmt.Errorf("HashBytesWith unsupported content hash algorithm %q", algorithm)
+	}
+}
+
+// IsHashAlgorithm check whether algorithm is supported
+func IsHashAlgorithm(algorithm string) bool {
+	switch algorithm {
+	case HashSha256, HashXxhash64, HashCrc32, HashSizeMtime:
+		return true
+	}
+	return false
+}
+
+// IsUniqueHash check whether equal hashes of two files mean equal content, only such hashes can pair renamed files
+// (crc32 collides too often, size and modification time are shared by unrelated files)
+func IsUniqueHash(algorithm string) bool {
+	return algorithm == HashSha256 || algorithm == HashXxhash64
+}
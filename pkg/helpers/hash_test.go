@@ -0,0 +1,52 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestHashBytesWith(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		input     string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "Test 1",
+			algorithm: HashSha256,
+			input:     "hello",
+			want:      "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		{
+			name:      "Test 2",
+			algorithm: HashCrc32,
+			input:     "hello",
+			want:      "3610a686",
+		},
+		{
+			name:      "Test 3",
+			algorithm: HashSizeMtime,
+			input:     "hello",
+			wantErr:   true,
+		},
+		{
+			name:      "Test 4",
+			algorithm: HashXxhash64,
+			input:     "hello",
+			want:      "26c7827d889f6da3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HashBytesWith(tt.algorithm, []byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HashBytesWith() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("HashBytesWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JsonPath returns value from JSON document on dotted path (e.g. "data.items.0.id", leading "$." is optional)
+func JsonPath(data []byte, path string) (any, error) {
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("JsonPath error while unmarshalling document: %w", err)
+	}
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return document, nil
+	}
+	current := document
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("JsonPath key %s of path %s not found", key, path)
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("JsonPath index %s of path %s is out of range", key, path)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("JsonPath key %s of path %s can not be applied to scalar value", key, path)
+		}
+	}
+	return current, nil
+}
+
+// JsonPathString returns value from JSON document on dotted path converted to string,
+// strings are returned as they are, other values are JSON encoded
+func JsonPathString(data []byte, path string) (string, error) {
+	value, err := JsonPath(data, path)
+	if err != nil {
+		return "", err
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("JsonPathString error while marshalling value of path %s: %w", path, err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestJsonPathString(t *testing.T) {
+	type args struct {
+		data string
+		path string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Test 1",
+			args: args{
+				data: `{"status": "ok"}`,
+				path: "status",
+			},
+			want: "ok",
+		},
+		{
+			name: "Test 2",
+			args: args{
+				data: `{"data": {"items": [{"id": 1}, {"id": 2}]}}`,
+				path: "$.data.items.1.id",
+			},
+			want: "2",
+		},
+		{
+			name: "Test 3",
+			args: args{
+				data: `{"data": {"ready": true}}`,
+				path: "data.ready",
+			},
+			want: "true",
+		},
+		{
+			name: "Test 4",
+			args: args{
+				data: `{"data": []}`,
+				path: "data.0",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test 5",
+			args: args{
+				data: `{"status": "ok"}`,
+				path: "status.code",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JsonPathString([]byte(tt.args.data), tt.args.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("JsonPathString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("JsonPathString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package helpers
+
+import "strings"
+
+// AppendSuffix key suffix which appends list to the list of base instead of replacing it
+const AppendSuffix = "+"
+
+// DeepMerge merge override into base and return new map, inputs are not modified
+//   - maps are merged recursively
+//   - lists and scalar values of override replace values of base
+//   - list with key ending by "+" (e.g. "action+") is appended to the list of base under key without suffix
+//   - null value of override removes key from base
+func DeepMerge(base map[string]any, override map[string]any) map[string]any {
+	res := make(map[string]any, len(base)+len(override))
+	for k, v := range base {
+		res[k] = v
+	}
+	for k, v := range override {
+		if strings.HasSuffix(k, AppendSuffix) {
+			key := strings.TrimSuffix(k, AppendSuffix)
+			baseList, _ := res[key].([]any)
+			overrideList, ok := v.([]any)
+			if !ok {
+				res[key] = v
+				continue
+			}
+			merged := make([]any, 0, len(baseList)+len(overrideList))
+			merged = append(merged, baseList...)
+			res[key] = append(merged, overrideList...)
+			continue
+		}
+		if v == nil {
+			delete(res, k)
+			continue
+		}
+		baseMap, baseOk := res[k].(map[string]any)
+		overrideMap, overrideOk := v.(map[string]any)
+		if baseOk && overrideOk {
+			res[k] = DeepMerge(baseMap, overrideMap)
+			continue
+		}
+		if overrideOk {
+			// strip append suffixes and null values from maps without counterpart in base
+			res[k] = DeepMerge(map[string]any{}, overrideMap)
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDeepMerge(t *testing.T) {
+	type args struct {
+		base     string
+		override string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "Test 1",
+			args: args{
+				base:     `{"sync": {"source": "/app", "destination": "/www"}}`,
+				override: `{"sync": {"destination": "/staging"}}`,
+			},
+			want: `{"sync": {"source": "/app", "destination": "/staging"}}`,
+		},
+		{
+			name: "Test 2",
+			args: args{
+				base:     `{"sync": {"ignore_list": ["a", "b"]}}`,
+				override: `{"sync": {"ignore_list": ["c"]}}`,
+			},
+			want: `{"sync": {"ignore_list": ["c"]}}`,
+		},
+		{
+			name: "Test 3",
+			args: args{
+				base:     `{"after": {"action": [{"type": "a"}]}}`,
+				override: `{"after": {"action+": [{"type": "b"}]}}`,
+			},
+			want: `{"after": {"action": [{"type": "a"}, {"type": "b"}]}}`,
+		},
+		{
+			name: "Test 4",
+			args: args{
+				base:     `{}`,
+				override: `{"after": {"action+": [{"type": "b"}]}}`,
+			},
+			want: `{"after": {"action": [{"type": "b"}]}}`,
+		},
+		{
+			name: "Test 5",
+			args: args{
+				base:     `{"folders": ["a"], "readable_folders": ["b"]}`,
+				override: `{"folders": null}`,
+			},
+			want: `{"readable_folders": ["b"]}`,
+		},
+		{
+			name: "Test 6",
+			args: args{
+				base:     `{"sync": {"ftp_config": {"host": "a", "user": "b"}}}`,
+				override: `{"sync": "replaced"}`,
+			},
+			want: `{"sync": "replaced"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var base, override, want map[string]any
+			_ = json.Unmarshal([]byte(tt.args.base), &base)
+			_ = json.Unmarshal([]byte(tt.args.override), &override)
+			_ = json.Unmarshal([]byte(tt.want), &want)
+			if got := DeepMerge(base, override); !reflect.DeepEqual(got, want) {
+				t.Errorf("DeepMerge() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -2,52 +2,124 @@ package helpers
 
 import (
 	"context"
+	"sort"
 	"sync"
 )
 
 type WorkerResult struct {
-	result any
-	err    error
+	index   int
+	result  any
+	err     error
+	skipped bool
 }
 
+// WorkerError error of single job returned by RunAllWorkers
+type WorkerError[T any] struct {
+	Input T
+	Err   error
+}
+
+// RunWorkers process input by numWorkers workers, first error cancels context of other workers,
+// jobs not started yet are skipped and the error is returned after all workers are done
 func RunWorkers[T any, K any](ctx context.Context, numWorkers int, input []T, worker func(ctx context.Context, input T) (K, error)) ([]K, error) {
+	wCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var firstErr error
+	result := make([]K, 0, len(input))
+	runWorkers(wCtx, numWorkers, input, worker, func(o WorkerResult) {
+		if o.skipped {
+			return
+		}
+		if o.err != nil {
+			if firstErr == nil {
+				firstErr = o.err
+				cancel()
+			}
+			return
+		}
+		if o.result != nil {
+			result = append(result, o.result.(K))
+		}
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// jobs were skipped because parent context was canceled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RunAllWorkers process whole input by numWorkers workers regardless of errors,
+// failed jobs are returned in input order
+func RunAllWorkers[T any, K any](ctx context.Context, numWorkers int, input []T, worker func(ctx context.Context, input T) (K, error)) ([]K, []WorkerError[T]) {
+	result := make([]K, 0, len(input))
+	failed := make([]WorkerResult, 0)
+	runWorkers(ctx, numWorkers, input, worker, func(o WorkerResult) {
+		if o.err != nil {
+			failed = append(failed, o)
+			return
+		}
+		if o.result != nil {
+			result = append(result, o.result.(K))
+		}
+	})
+
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].index < failed[j].index
+	})
+	errs := make([]WorkerError[T], len(failed))
+	for i, o := range failed {
+		errs[i] = WorkerError[T]{Input: input[o.index], Err: o.err}
+	}
+	return result, errs
+}
+
+// runWorkers send all jobs to workers and pass every result to handle, it returns when all workers are done,
+// jobs received after context is canceled are not processed and their result is marked as skipped
+func runWorkers[T any, K any](ctx context.Context, numWorkers int, input []T, worker func(ctx context.Context, input T) (K, error), handle func(o WorkerResult)) {
 	// if input is lower that number of workers set number of workers to input length
 	if len(input) < numWorkers {
 		numWorkers = len(input)
 	}
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
 
-	buff := func() int {
-		maxSize := 100
-		if size := len(input); size <= maxSize {
-			return size
-		}
-		return maxSize
-	}()
+	type job struct {
+		index int
+		input T
+	}
 
 	// prepare channels and wait group
-	jobs := make(chan T, buff)
-	resChan := make(chan WorkerResult, buff)
+	jobs := make(chan job)
+	resChan := make(chan WorkerResult, numWorkers)
 	wg := sync.WaitGroup{}
 
 	// start workers
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
-		go func(fCtx context.Context, fJobs <-chan T, fResChan chan<- WorkerResult) {
-			defer func() {
-				wg.Done()
-			}()
-
-			for job := range fJobs {
-				result, err := worker(ctx, job)
-				fResChan <- WorkerResult{result: result, err: err}
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				if err := ctx.Err(); err != nil {
+					resChan <- WorkerResult{index: j.index, err: err, skipped: true}
+					continue
+				}
+				result, err := worker(ctx, j.input)
+				resChan <- WorkerResult{index: j.index, result: result, err: err}
 			}
-		}(ctx, jobs, resChan)
+		}()
 	}
 
 	go func() {
-		// send all jobs to workers
-		for _, job := range input {
-			jobs <- job
+		// send all jobs to workers, canceled jobs are skipped by workers
+		for i, in := range input {
+			jobs <- job{index: i, input: in}
 		}
 		// close channel no more jobs
 		close(jobs)
@@ -57,16 +129,8 @@ func RunWorkers[T any, K any](ctx context.Context, numWorkers int, input []T, wo
 		close(resChan)
 	}()
 
-	// process results
-	result := make([]K, 0, len(input))
+	// process results until all workers are done
 	for o := range resChan {
-		if o.err != nil {
-			return nil, o.err
-		}
-		if o.result != nil {
-			result = append(result, o.result.(K))
-		}
+		handle(o)
 	}
-
-	return result, nil
 }
@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunWorkers(t *testing.T) {
+	input := make([]int, 100)
+	for i := range input {
+		input[i] = i
+	}
+	var started atomic.Int32
+	_, err := RunWorkers(context.Background(), 2, input, func(ctx context.Context, i int) (int, error) {
+		started.Add(1)
+		if i == 3 {
+			return 0, fmt.Errorf("job %d failed", i)
+		}
+		return i, nil
+	})
+	if err == nil || err.Error() != "job 3 failed" {
+		t.Errorf("RunWorkers() error = %v, want %v", err, "job 3 failed")
+	}
+	if got := started.Load(); got == int32(len(input)) {
+		t.Errorf("RunWorkers() started %v jobs, want jobs after error to be skipped", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := RunWorkers(ctx, 2, input, func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("RunWorkers() error = %v, want %v", err, context.Canceled)
+	}
+
+	got, err := RunWorkers(context.Background(), 3, input, func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	})
+	if err != nil {
+		t.Fatalf("RunWorkers() error = %v", err)
+	}
+	sort.Ints(got)
+	if !reflect.DeepEqual(got, input) {
+		t.Errorf("RunWorkers() = %v, want %v", got, input)
+	}
+}
+
+func TestRunAllWorkers(t *testing.T) {
+	input := []string{"/a", "/b", "/c", "/d", "/e"}
+	got, errs := RunAllWorkers(context.Background(), 2, input, func(ctx context.Context, path string) (string, error) {
+		if path == "/b" || path == "/e" {
+			return "", fmt.Errorf("upload of %s failed", path)
+		}
+		return path, nil
+	})
+	sort.Strings(got)
+	if want := []string{"/a", "/c", "/d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RunAllWorkers() = %v, want %v", got, want)
+	}
+	failed := make([]string, len(errs))
+	for i, e := range errs {
+		failed[i] = e.Input
+	}
+	if want := []string{"/b", "/e"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("RunAllWorkers() failed = %v, want %v", failed, want)
+	}
+}
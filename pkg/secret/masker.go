@@ -0,0 +1,68 @@
+package secret
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+const mask = "*****"
+
+// minimal length of masked value, shorter values would mask common words in logs
+const minMaskedLength = 4
+
+// Masker track resolved secret values and replace them in text
+type Masker struct {
+	mu       sync.RWMutex
+	values   []string
+	replacer *strings.Replacer
+}
+
+func NewMasker() *Masker {
+	return &Masker{}
+}
+
+func (m *Masker) Add(value string) {
+	if len(value) < minMaskedLength {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, v := range m.values {
+		if v == value {
+			return
+		}
+	}
+	m.values = append(m.values, value)
+	pairs := make([]string, 0, len(m.values)*2)
+	for _, v := range m.values {
+		pairs = append(pairs, v, mask)
+	}
+	m.replacer = strings.NewReplacer(pairs...)
+}
+
+func (m *Masker) Mask(text string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.replacer == nil {
+		return text
+	}
+	return m.replacer.Replace(text)
+}
+
+// MaskingWriter mask secrets in everything written to underlying writer
+type MaskingWriter struct {
+	writer io.Writer
+	masker *Masker
+}
+
+func NewMaskingWriter(writer io.Writer, masker *Masker) *MaskingWriter {
+	return &MaskingWriter{writer: writer, masker: masker}
+}
+
+func (w *MaskingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.writer, w.masker.Mask(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
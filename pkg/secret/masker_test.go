@@ -0,0 +1,50 @@
+package secret
+
+import (
+	"testing"
+)
+
+func TestMasker_Mask(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		text   string
+		want   string
+	}{
+		{
+			name:   "Test 1",
+			values: []string{"s3cr3t-token"},
+			text:   "calling https://example.com/?token=s3cr3t-token failed",
+			want:   "calling https://example.com/?token=***** failed",
+		},
+		{
+			name:   "Test 2",
+			values: []string{"password1", "s3cr3t-token"},
+			text:   "user:password1 token:s3cr3t-token",
+			want:   "user:***** token:*****",
+		},
+		{
+			name:   "Test 3",
+			values: []string{"abc"},
+			text:   "abc is too short to be masked",
+			want:   "abc is too short to be masked",
+		},
+		{
+			name:   "Test 4",
+			values: nil,
+			text:   "nothing to mask",
+			want:   "nothing to mask",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMasker()
+			for _, v := range tt.values {
+				m.Add(v)
+			}
+			if got := m.Mask(tt.text); got != tt.want {
+				t.Errorf("Mask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type Provider interface {
+	Resolve(ctx context.Context, reference string) (string, error)
+}
+
+// FileProvider read secret from file, trailing new line is removed
+type FileProvider struct {
+}
+
+func NewFileProvider() *FileProvider {
+	return &FileProvider{}
+}
+
+func (f FileProvider) Resolve(_ context.Context, reference string) (string, error) {
+	content, err := os.ReadFile(reference)
+	if err != nil {
+		return "", fmt.Errorf("FileProvider::Resolve error while reading file %s: %w", reference, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// CommandProvider read secret from stdout of local command run by shell
+type CommandProvider struct {
+}
+
+func NewCommandProvider() *CommandProvider {
+	return &CommandProvider{}
+}
+
+func (c CommandProvider) Resolve(ctx context.Context, reference string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", reference)
+	stdout := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("CommandProvider::Resolve error while running command: %w", err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// EnvProvider read secret from environment variable
+type EnvProvider struct {
+}
+
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{}
+}
+
+func (e EnvProvider) Resolve(_ context.Context, reference string) (string, error) {
+	value, ok := os.LookupEnv(reference)
+	if !ok {
+		return "", fmt.Errorf("EnvProvider::Resolve environment variable %s is not set", reference)
+	}
+	return value, nil
+}
+
+// EncryptedFileProvider read secret from encrypted secrets file, reference is in format path#NAME
+type EncryptedFileProvider struct {
+}
+
+func NewEncryptedFileProvider() *EncryptedFileProvider {
+	return &EncryptedFileProvider{}
+}
+
+func (e EncryptedFileProvider) Resolve(_ context.Context, reference string) (string, error) {
+	path, name, ok := strings.Cut(reference, "#")
+	if !ok {
+		return "", fmt.Errorf("EncryptedFileProvider::Resolve reference %s must be in format path#NAME", reference)
+	}
+	key, err := LoadKey()
+	if err != nil {
+		return "", fmt.Errorf("EncryptedFileProvider::Resolve error while loading key: %w", err)
+	}
+	store, err := ReadStore(path)
+	if err != nil {
+		return "", fmt.Errorf("EncryptedFileProvider::Resolve error while reading secrets file: %w", err)
+	}
+	value, err := store.Get(key, name)
+	if err != nil {
+		return "", fmt.Errorf("EncryptedFileProvider::Resolve error while decrypting secret %s: %w", name, err)
+	}
+	return value, nil
+}
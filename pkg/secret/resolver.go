@@ -0,0 +1,49 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	SchemeFile      = "file"
+	SchemeCommand   = "command"
+	SchemeEnv       = "env"
+	SchemeEncrypted = "encrypted"
+)
+
+// Resolver resolve secret references in format scheme:reference and track resolved values in masker
+type Resolver struct {
+	providers map[string]Provider
+	masker    *Masker
+}
+
+func NewResolver(masker *Masker) *Resolver {
+	return &Resolver{
+		providers: map[string]Provider{
+			SchemeFile:      NewFileProvider(),
+			SchemeCommand:   NewCommandProvider(),
+			SchemeEnv:       NewEnvProvider(),
+			SchemeEncrypted: NewEncryptedFileProvider(),
+		},
+		masker: masker,
+	}
+}
+
+func (r *Resolver) Resolve(ctx context.Context, reference string) (string, error) {
+	scheme, rest, ok := strings.Cut(reference, ":")
+	if !ok {
+		return "", fmt.Errorf("Resolver::Resolve reference must be in format scheme:reference")
+	}
+	provider, ok := r.providers[scheme]
+	if !ok {
+		return "", fmt.Errorf("Resolver::Resolve unknown secret scheme: %s", scheme)
+	}
+	value, err := provider.Resolve(ctx, rest)
+	if err != nil {
+		return "", fmt.Errorf("Resolver::Resolve error while resolving %s secret: %w", scheme, err)
+	}
+	r.masker.Add(value)
+	return value, nil
+}
@@ -0,0 +1,244 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const KeyEnv = "DEPLOYER_SECRETS_KEY"
+const KeyFileEnv = "DEPLOYER_SECRETS_KEY_FILE"
+
+const storeVersion = 1
+
+const (
+	kdfArgon2id   = "argon2id"
+	kdfSaltSize   = 16
+	kdfKeySize    = 32
+	kdfTime       = 3
+	kdfMemory     = 64 * 1024
+	kdfThreads    = 4
+	kdfMaxMemory  = 1024 * 1024
+	kdfMaxTime    = 16
+	kdfMaxThreads = 64
+)
+
+// Kdf parameters of key derivation from passphrase, salt is random for every file
+type Kdf struct {
+	Algorithm string `json:"algorithm"`
+	Salt      string `json:"salt"`
+	Time      uint32 `json:"time"`
+	Memory    uint32 `json:"memory"`
+	Threads   uint8  `json:"threads"`
+}
+
+// Store is a file of AES-GCM encrypted values, names stay readable so the file can be reviewed in git
+type Store struct {
+	Version int               `json:"version"`
+	Kdf     *Kdf              `json:"kdf,omitempty"`
+	Values  map[string]string `json:"values"`
+
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+func NewStore() (*Store, error) {
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("NewStore error while generating salt: %w", err)
+	}
+	return &Store{
+		Version: storeVersion,
+		Kdf: &Kdf{
+			Algorithm: kdfArgon2id,
+			Salt:      base64.StdEncoding.EncodeToString(salt),
+			Time:      kdfTime,
+			Memory:    kdfMemory,
+			Threads:   kdfThreads,
+		},
+		Values: make(map[string]string),
+	}, nil
+}
+
+// LoadKey load passphrase from DEPLOYER_SECRETS_KEY or from file in DEPLOYER_SECRETS_KEY_FILE,
+// encryption key is derived from it by every store with its own salt
+func LoadKey() ([]byte, error) {
+	material, ok := os.LookupEnv(KeyEnv)
+	if !ok {
+		path, ok := os.LookupEnv(KeyFileEnv)
+		if !ok {
+			return nil, fmt.Errorf("LoadKey neither %s nor %s is set", KeyEnv, KeyFileEnv)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("LoadKey error while reading key file %s: %w", path, err)
+		}
+		material = strings.TrimSpace(string(content))
+	}
+	if material == "" {
+		return nil, errors.New("LoadKey key is empty")
+	}
+	return []byte(material), nil
+}
+
+func ReadStore(path string) (*Store, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadStore error while reading file %s: %w", path, err)
+	}
+	store := &Store{}
+	if err := json.Unmarshal(content, store); err != nil {
+		return nil, fmt.Errorf("ReadStore error while unmarshalling file %s: %w", path, err)
+	}
+	if store.Version != storeVersion {
+		return nil, fmt.Errorf("ReadStore unsupported version %d of file %s", store.Version, path)
+	}
+	if err := store.Kdf.validate(); err != nil {
+		return nil, fmt.Errorf("ReadStore invalid key derivation of file %s: %w", path, err)
+	}
+	if store.Values == nil {
+		store.Values = make(map[string]string)
+	}
+	return store, nil
+}
+
+func (s *Store) Write(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Store::Write error while marshalling store: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		return fmt.Errorf("Store::Write error while writing file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.Values))
+	for name := range s.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Store) Get(passphrase []byte, name string) (string, error) {
+	encrypted, ok := s.Values[name]
+	if !ok {
+		return "", fmt.Errorf("Store::Get secret %s does not exist", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("Store::Get error while decoding secret %s: %w", name, err)
+	}
+	gcm, err := s.gcm(passphrase)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("Store::Get secret %s is too short", name)
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("Store::Get error while decrypting secret %s (wrong key?): %w", name, err)
+	}
+	return string(plain), nil
+}
+
+func (s *Store) Set(passphrase []byte, name string, value string) error {
+	gcm, err := s.gcm(passphrase)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("Store::Set error while generating nonce: %w", err)
+	}
+	s.Values[name] = base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), []byte(name)))
+	return nil
+}
+
+// Decrypt return all decrypted values
+func (s *Store) Decrypt(passphrase []byte) (map[string]string, error) {
+	res := make(map[string]string, len(s.Values))
+	for name := range s.Values {
+		value, err := s.Get(passphrase, name)
+		if err != nil {
+			return nil, err
+		}
+		res[name] = value
+	}
+	return res, nil
+}
+
+// Store::gcm create cipher with key derived from passphrase, derived keys are cached because derivation is slow on purpose
+func (s *Store) gcm(passphrase []byte) (cipher.AEAD, error) {
+	key, err := s.key(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return newGcm(key)
+}
+
+func (s *Store) key(passphrase []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.Kdf.validate(); err != nil {
+		return nil, fmt.Errorf("Store::key invalid key derivation: %w", err)
+	}
+	id := sha256.Sum256(passphrase)
+	if key, ok := s.keys[string(id[:])]; ok {
+		return key, nil
+	}
+	salt, err := base64.StdEncoding.DecodeString(s.Kdf.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("Store::key error while decoding salt: %w", err)
+	}
+	key := argon2.IDKey(passphrase, salt, s.Kdf.Time, s.Kdf.Memory, s.Kdf.Threads, kdfKeySize)
+	if s.keys == nil {
+		s.keys = make(map[string][]byte)
+	}
+	s.keys[string(id[:])] = key
+	return key, nil
+}
+
+// Kdf::validate check parameters read from file, limits protect from files requiring huge memory
+func (k *Kdf) validate() error {
+	if k == nil {
+		return errors.New("missing kdf")
+	}
+	if k.Algorithm != kdfArgon2id {
+		return fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+	salt, err := base64.StdEncoding.DecodeString(k.Salt)
+	if err != nil || len(salt) < kdfSaltSize {
+		return errors.New("invalid salt")
+	}
+	if k.Time < 1 || k.Time > kdfMaxTime || k.Memory < 8*uint32(k.Threads) || k.Memory > kdfMaxMemory || k.Threads < 1 || k.Threads > kdfMaxThreads {
+		return errors.New("invalid parameters")
+	}
+	return nil
+}
+
+func newGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("newGcm error while creating cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("newGcm error while creating GCM: %w", err)
+	}
+	return gcm, nil
+}
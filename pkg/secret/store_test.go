@@ -0,0 +1,76 @@
+package secret
+
+import (
+	"crypto/sha256"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_SetGet(t *testing.T) {
+	key := sha256.Sum256([]byte("key"))
+	wrongKey := sha256.Sum256([]byte("wrong"))
+
+	store, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if err := store.Set(key[:], "FTP_PASSWORD", "p&ss<word>"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, err := store.Get(key[:], "FTP_PASSWORD")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != "p&ss<word>" {
+		t.Errorf("Get() = %v, want %v", got, "p&ss<word>")
+	}
+
+	if _, err := store.Get(wrongKey[:], "FTP_PASSWORD"); err == nil {
+		t.Errorf("Get() with wrong key should fail")
+	}
+
+	// encrypted value is bound to its name
+	store.Values["OTHER"] = store.Values["FTP_PASSWORD"]
+	if _, err := store.Get(key[:], "OTHER"); err == nil {
+		t.Errorf("Get() of value copied under other name should fail")
+	}
+}
+
+func TestStore_Kdf(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	first, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	second, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if first.Kdf.Salt == second.Kdf.Salt {
+		t.Errorf("NewStore() salt is not random")
+	}
+	if err := first.Set(passphrase, "FTP_PASSWORD", "secret"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := first.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	read, err := ReadStore(path)
+	if err != nil {
+		t.Fatalf("ReadStore() error = %v", err)
+	}
+	if got, err := read.Get(passphrase, "FTP_PASSWORD"); err != nil || got != "secret" {
+		t.Errorf("Get() = %v, %v, want %v", got, err, "secret")
+	}
+
+	read.Kdf.Memory = 1 << 30
+	if err := read.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := ReadStore(path); err == nil {
+		t.Errorf("ReadStore() with huge memory parameter should fail")
+	}
+}
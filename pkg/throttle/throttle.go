@@ -0,0 +1,205 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maximum number of bytes read at once, so waiting is spread evenly over the transfer
+const chunkSize = 32 * 1024
+
+var units = map[string]float64{
+	"":   1,
+	"B":  1,
+	"K":  1024,
+	"KB": 1024,
+	"M":  1024 * 1024,
+	"MB": 1024 * 1024,
+	"G":  1024 * 1024 * 1024,
+	"GB": 1024 * 1024 * 1024,
+}
+
+// ParseRate parse rate like "2MB/s", "512KB/s" or "1000" into bytes per second, empty string or zero means unlimited
+func ParseRate(value string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(value))
+	v = strings.TrimSuffix(v, "/S")
+	if v == "" {
+		return 0, nil
+	}
+	i := strings.IndexFunc(v, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := v, ""
+	if i >= 0 {
+		number, unit = strings.TrimSpace(v[:i]), strings.TrimSpace(v[i:])
+	}
+	multiplier, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("ParseRate unknown unit %q in rate %q", unit, value)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("ParseRate invalid rate %q", value)
+	}
+	return int64(n * multiplier), nil
+}
+
+// Bucket token bucket limiting throughput in bytes per second, shared by all goroutines, zero rate means unlimited
+type Bucket struct {
+	mu     sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+	// closed when rate is changed, so waiting transfers recompute their delay
+	changed chan struct{}
+}
+
+func NewBucket(rate int64) *Bucket {
+	return &Bucket{rate: rate, tokens: float64(rate), last: time.Now(), changed: make(chan struct{})}
+}
+
+// Bucket::SetRate change rate, waiting transfers are woken up and wait by the new rate
+func (b *Bucket) SetRate(rate int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rate == b.rate {
+		return
+	}
+	switch {
+	case rate <= 0 || b.rate <= 0:
+		b.tokens = 0
+	case b.tokens < 0:
+		// debt of waiting transfers is paid by the new rate
+		b.tokens = b.tokens * float64(rate) / float64(b.rate)
+	case b.tokens > float64(rate):
+		b.tokens = float64(rate)
+	}
+	b.rate = rate
+	b.last = time.Now()
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Bucket::Rate return current rate
+func (b *Bucket) Rate() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate
+}
+
+// Bucket::Wait take n bytes from bucket and wait until they are available
+func (b *Bucket) Wait(ctx context.Context, n int) error {
+	delay, rate, changed := b.reserve(n)
+	if delay <= 0 {
+		return nil
+	}
+	deadline := time.Now().Add(delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+			b.mu.Lock()
+			newRate, newChanged := b.rate, b.changed
+			b.mu.Unlock()
+			remaining := time.Until(deadline)
+			if newRate <= 0 || remaining <= 0 {
+				return nil
+			}
+			remaining = time.Duration(float64(remaining) * float64(rate) / float64(newRate))
+			deadline = time.Now().Add(remaining)
+			rate, changed = newRate, newChanged
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(remaining)
+		}
+	}
+}
+
+func (b *Bucket) reserve(n int) (time.Duration, int64, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if b.rate <= 0 {
+		b.last = now
+		return 0, b.rate, b.changed
+	}
+	// refill, burst is limited to one second of transfer
+	b.tokens += now.Sub(b.last).Seconds() * float64(b.rate)
+	if b.tokens > float64(b.rate) {
+		b.tokens = float64(b.rate)
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0, b.rate, b.changed
+	}
+	return time.Duration(-b.tokens / float64(b.rate) * float64(time.Second)), b.rate, b.changed
+}
+
+// Throttle bandwidth limits of uploads, global rate is shared by all uploads, connection rate applies to every upload
+type Throttle struct {
+	global         *Bucket
+	connectionRate atomic.Int64
+}
+
+func NewThrottle(globalRate int64, connectionRate int64) *Throttle {
+	t := &Throttle{global: NewBucket(globalRate)}
+	t.connectionRate.Store(connectionRate)
+	return t
+}
+
+// Throttle::SetRates change rates of running and future uploads
+func (t *Throttle) SetRates(globalRate int64, connectionRate int64) {
+	t.global.SetRate(globalRate)
+	t.connectionRate.Store(connectionRate)
+}
+
+// Throttle::Rates return global and connection rate
+func (t *Throttle) Rates() (int64, int64) {
+	return t.global.Rate(), t.connectionRate.Load()
+}
+
+// Throttle::Reader wrap reader of single upload, nil throttle does not limit anything
+func (t *Throttle) Reader(ctx context.Context, r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &reader{ctx: ctx, reader: r, throttle: t, connection: NewBucket(t.connectionRate.Load())}
+}
+
+type reader struct {
+	ctx        context.Context
+	reader     io.Reader
+	throttle   *Throttle
+	connection *Bucket
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > chunkSize {
+		p = p[:chunkSize]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		// connection rate changed at runtime applies to running uploads too
+		r.connection.SetRate(r.throttle.connectionRate.Load())
+		if wErr := r.connection.Wait(r.ctx, n); wErr != nil {
+			return n, wErr
+		}
+		if wErr := r.throttle.global.Wait(r.ctx, n); wErr != nil {
+			return n, wErr
+		}
+	}
+	return n, err
+}
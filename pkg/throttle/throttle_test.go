@@ -0,0 +1,92 @@
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "Test 1", value: "2MB/s", want: 2 * 1024 * 1024},
+		{name: "Test 2", value: "512 kb/s", want: 512 * 1024},
+		{name: "Test 3", value: "1.5M", want: 1536 * 1024},
+		{name: "Test 4", value: "1000", want: 1000},
+		{name: "Test 5", value: "", want: 0},
+		{name: "Test 6", value: "2TB/s", wantErr: true},
+		{name: "Test 7", value: "fast", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThrottle_Reader(t *testing.T) {
+	tests := []struct {
+		name           string
+		globalRate     int64
+		connectionRate int64
+		size           int
+		readers        int
+		minDuration    time.Duration
+	}{
+		{name: "Test 1", size: 1024 * 1024, readers: 2, minDuration: 0},
+		// first second is burst, other 32KB are limited by global rate
+		{name: "Test 2", globalRate: 64 * 1024, size: 48 * 1024, readers: 2, minDuration: 400 * time.Millisecond},
+		{name: "Test 3", connectionRate: 64 * 1024, size: 96 * 1024, readers: 1, minDuration: 400 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle := NewThrottle(tt.globalRate, tt.connectionRate)
+			started := time.Now()
+			done := make(chan error, tt.readers)
+			for i := 0; i < tt.readers; i++ {
+				go func() {
+					_, err := io.Copy(io.Discard, throttle.Reader(context.Background(), bytes.NewReader(make([]byte, tt.size))))
+					done <- err
+				}()
+			}
+			for i := 0; i < tt.readers; i++ {
+				if err := <-done; err != nil {
+					t.Fatalf("Read() error = %v", err)
+				}
+			}
+			if elapsed := time.Since(started); elapsed < tt.minDuration {
+				t.Errorf("Read() took %v, want at least %v", elapsed, tt.minDuration)
+			}
+		})
+	}
+}
+
+func TestThrottle_SetRates(t *testing.T) {
+	throttle := NewThrottle(1024, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	// lift the limit while upload is waiting
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		throttle.SetRates(0, 0)
+	}()
+	if _, err := io.Copy(io.Discard, throttle.Reader(ctx, bytes.NewReader(make([]byte, 1024*1024)))); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if global, connection := throttle.Rates(); global != 0 || connection != 0 {
+		t.Errorf("Rates() = %v, %v, want 0, 0", global, connection)
+	}
+}